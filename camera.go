@@ -5,36 +5,104 @@ package dango
 
 import (
 	"fmt"
+	"image"
 	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/image/math/f64"
 )
 
+// BoundsAlign is where the view is placed along an axis when the world
+// bounds are smaller than the visible area
+type BoundsAlign int
+
+const (
+	BoundsCenter BoundsAlign = iota // centre of the bounds
+	BoundsMin                       // pin to the left/top edge
+	BoundsMax                       // pin to the right/bottom edge
+)
+
 // Camera projects world to Screen
 type Camera struct {
 	ViewPort   f64.Vec2 // viewport should be the same as the window size/resolution
+	Offset     f64.Vec2 // top left of the viewport on screen, e.g. for split screen
 	Position   f64.Vec2 // points camera to `Position` in the world
 	ZoomFactor int
 	Rotation   float64
+	cache      matrixCache
+	follow     followState
+	bounds     cameraBounds
+	shake      shakeState
+	tweens     []*CameraTween
+	scale      float64 // scale multiplied with ZoomFactor, 0 only in a zero value Camera, is 1
+	minScale   float64 // 0 for no limit
+	maxScale   float64
+	pixel      pixelState
+}
+
+// matrixCache keeps the camera matrix and its inverse until the state they
+// were built from changes. Camera fields are public and can change without a
+// setter, so the cache compares a snapshot of the state instead of using a
+// changed flag
+type matrixCache struct {
+	valid      bool
+	state      matrixState
 	matrix     ebiten.GeoM
+	inverse    ebiten.GeoM
+	invertible bool
+}
+
+// matrixState is everything worldMatrix depends on
+type matrixState struct {
+	viewPort    f64.Vec2
+	offset      f64.Vec2
+	position    f64.Vec2
+	zoomFactor  int
+	rotation    float64
+	scale       float64
+	pixel       bool
+	trauma      float64
+	shakeOffset f64.Vec2
+	shakeAngle  float64
+}
+
+type cameraBounds struct {
+	enabled bool
+	min     f64.Vec2
+	max     f64.Vec2
+	align   BoundsAlign
 }
 
 func (c *Camera) String() string {
 	return fmt.Sprintf(
-		"T: %.1f, R: %.02f, S: %d",
-		c.Position, c.Rotation, c.ZoomFactor,
+		"T: %.1f, R: %.02f, S: %.02f",
+		c.Position, c.Rotation, c.Scale(),
 	)
 }
 
 // SetViewPort set the size of the window
 func (c *Camera) SetViewPort(w, h int) {
 	c.ViewPort = [2]float64{float64(w), float64(h)}
+	c.clampPosition()
+}
+
+// SetViewPortRect set the viewport to rectangle x, y, w, h on screen,
+// e.g. one half of a split screen
+func (c *Camera) SetViewPortRect(x, y, w, h int) {
+	c.Offset = f64.Vec2{float64(x), float64(y)}
+	c.SetViewPort(w, h)
+}
+
+// ViewPortRect return the viewport on screen
+func (c *Camera) ViewPortRect() image.Rectangle {
+	x0, y0, x1, y1 := c.viewportRect()
+	return image.Rect(int(math.Floor(x0)), int(math.Floor(y0)), int(math.Ceil(x1)), int(math.Ceil(y1)))
 }
 
 // SetPosition moves camera to location x, y
 func (c *Camera) SetPosition(x, y float64) {
 	c.Position = [2]float64{x, y}
+	c.clampPosition()
 }
 
 // GetPosition return x, y
@@ -45,48 +113,212 @@ func (c *Camera) GetPosition() (float64, float64) {
 func (c *Camera) Pan(x, y float64) {
 	c.Position[0] += x*math.Cos(-c.Rotation) - y*math.Sin(-c.Rotation)
 	c.Position[1] += x*math.Sin(-c.Rotation) + y*math.Cos(-c.Rotation)
+	c.clampPosition()
 }
 
 func (c *Camera) Rotate(a float64) {
 	c.Rotation += a
+	c.clampPosition()
 }
 
+// SetRotation set camera rotation to `a` radian
+func (c *Camera) SetRotation(a float64) {
+	c.Rotation = a
+	c.clampPosition()
+}
+
+// ZoomIn scales by 1.01^n
 func (c *Camera) ZoomIn(n int) {
 	c.ZoomFactor += n
+	c.clampScale()
+	c.clampPosition()
 }
 
+// ZoomOut scales by 1.01^-n
 func (c *Camera) ZoomOut(n int) {
 	c.ZoomFactor -= n
+	c.clampScale()
+	c.clampPosition()
+}
+
+// SetScale set the zoom to exact scale `s`, e.g. 2 for 2x, 0.5 for half size,
+// `s` <= 0 is ignored
+func (c *Camera) SetScale(s float64) {
+	if !(s > 0) {
+		return
+	}
+	c.ZoomFactor = 0
+	c.scale = s
+	c.clampScale()
+	c.clampPosition()
+}
+
+// ZoomBy multiplies the scale by `factor`, zooming at the viewport centre
+func (c *Camera) ZoomBy(factor float64) {
+	c.SetScale(c.Scale() * factor)
+}
+
+// ZoomAt multiplies the scale by `factor`, keeping the world point under
+// screen position screenX, screenY fixed, e.g. zoom at the cursor
+func (c *Camera) ZoomAt(screenX, screenY, factor float64) {
+	mc := c.matrices()
+	if !mc.invertible {
+		return
+	}
+	wx, wy := mc.inverse.Apply(screenX, screenY)
+	c.ZoomBy(factor)
+	c.placeAt(wx, wy, screenX, screenY)
+}
+
+// placeAt moves the camera so world point wx, wy is at screen position
+// screenX, screenY
+func (c *Camera) placeAt(wx, wy, screenX, screenY float64) {
+	center := c.viewportCenter()
+	v := Vector{screenX - center[0], screenY - center[1]}.
+		Unrotate(ForAngle(c.Rotation)).Mult(1 / c.viewScale())
+	c.SetPosition(wx-v.X, wy-v.Y)
+}
+
+// SetZoomLimits limits the scale from `min` to `max`, 0 for no limit
+func (c *Camera) SetZoomLimits(min, max float64) {
+	c.minScale = min
+	c.maxScale = max
+	c.clampScale()
+	c.clampPosition()
+}
+
+// clampScale keeps the scale within the zoom limits, ZoomFactor is kept
+// so ZoomIn and ZoomOut respond immediately at the limits
+func (c *Camera) clampScale() {
+	s := c.Scale()
+	zoom := math.Pow(1.01, float64(c.ZoomFactor))
+	if c.maxScale > 0 && s > c.maxScale {
+		c.scale = c.maxScale / zoom
+	}
+	if c.minScale > 0 && s < c.minScale {
+		c.scale = c.minScale / zoom
+	}
+}
+
+// SetBounds keeps the visible area inside world rectangle
+// minX, minY to maxX, maxY, taking zoom, rotation and viewport into account
+func (c *Camera) SetBounds(minX, minY, maxX, maxY float64) {
+	c.bounds.enabled = true
+	c.bounds.min = f64.Vec2{math.Min(minX, maxX), math.Min(minY, maxY)}
+	c.bounds.max = f64.Vec2{math.Max(minX, maxX), math.Max(minY, maxY)}
+	c.clampPosition()
+}
+
+// SetBoundsAlign set where the view is placed when the bounds are smaller
+// than the visible area, default BoundsCenter
+func (c *Camera) SetBoundsAlign(a BoundsAlign) {
+	c.bounds.align = a
+	c.clampPosition()
+}
+
+// ClearBounds lets the camera move freely
+func (c *Camera) ClearBounds() {
+	c.bounds.enabled = false
+}
+
+// Bounds return the world bounds, ok is false when there are no bounds
+func (c *Camera) Bounds() (minX, minY, maxX, maxY float64, ok bool) {
+	b := c.bounds
+	return b.min[0], b.min[1], b.max[0], b.max[1], b.enabled
+}
+
+// clampPosition moves the camera so the visible area stays in bounds
+func (c *Camera) clampPosition() {
+	if !c.bounds.enabled {
+		return
+	}
+	// half size of the axis aligned box around the rotated viewport, in world
+	sin, cos := math.Abs(math.Sin(c.Rotation)), math.Abs(math.Cos(c.Rotation))
+	w, h := c.ViewPort[0]/2, c.ViewPort[1]/2
+	half := f64.Vec2{
+		(cos*w + sin*h) / c.viewScale(),
+		(sin*w + cos*h) / c.viewScale(),
+	}
+	for i := 0; i < 2; i++ {
+		lo := c.bounds.min[i] + half[i]
+		hi := c.bounds.max[i] - half[i]
+		if lo <= hi {
+			c.Position[i] = math.Max(lo, math.Min(c.Position[i], hi))
+			continue
+		}
+		// bounds smaller than the view
+		switch c.bounds.align {
+		case BoundsMin:
+			c.Position[i] = lo
+		case BoundsMax:
+			c.Position[i] = hi
+		default:
+			c.Position[i] = (c.bounds.min[i] + c.bounds.max[i]) / 2
+		}
+	}
 }
 
 func (c *Camera) viewportCenter() f64.Vec2 {
 	return f64.Vec2{
-		c.ViewPort[0] * 0.5,
-		c.ViewPort[1] * 0.5,
+		c.Offset[0] + c.ViewPort[0]*0.5,
+		c.Offset[1] + c.ViewPort[1]*0.5,
 	}
 }
 
-// UpdateMatrix when position/rotation/zoom changes
+// Update keeps the position inside the bounds after changing the fields
+// directly, the matrix follows position/rotation/zoom/viewport changes
+// without calling Update
 func (c *Camera) Update() {
-	c.matrix = c.worldMatrix()
+	c.clampPosition()
+	c.matrices()
+}
+
+// matrices return the camera matrix and its inverse, rebuilt only when
+// position, zoom, rotation, viewport or shake changed since the last call
+func (c *Camera) matrices() *matrixCache {
+	state := matrixState{
+		viewPort:    c.ViewPort,
+		offset:      c.Offset,
+		position:    c.Position,
+		zoomFactor:  c.ZoomFactor,
+		rotation:    c.Rotation,
+		scale:       c.scale,
+		pixel:       c.pixel.enabled,
+		trauma:      c.shake.trauma,
+		shakeOffset: c.shake.offset,
+		shakeAngle:  c.shake.angle,
+	}
+	mc := &c.cache
+	if mc.valid && mc.state == state {
+		return mc
+	}
+	mc.valid = true
+	mc.state = state
+	mc.matrix = c.worldMatrix()
+	mc.inverse = mc.matrix
+	mc.invertible = mc.inverse.IsInvertible()
+	if mc.invertible {
+		mc.inverse.Invert()
+	}
+	return mc
 }
 
 // Matrix return current camera matrix
 func (c *Camera) Matrix() ebiten.GeoM {
-	return c.matrix
+	return c.matrices().matrix
 }
 
 // Ebiten GeoM,
 // usage sprite.GeoM.Concat(camera.GeoM)
 func (c *Camera) GeoM() ebiten.GeoM {
-	return c.matrix
+	return c.matrices().matrix
 }
 
 // DEPRECATED,  Concat camera's matrix with m
 func (c *Camera) SpriteGeoMConcat(sprite ebiten.GeoM) ebiten.GeoM {
 	nm := ebiten.GeoM{}
 	nm.Concat(sprite)
-	nm.Concat(c.matrix)
+	nm.Concat(c.matrices().matrix)
 	return nm
 }
 
@@ -97,31 +329,75 @@ func (c *Camera) worldMatrix() ebiten.GeoM {
 	// m.Translate(-c.Position[0]+c.viewportCenter()[0], -c.Position[1]+c.viewportCenter()[1])
 	// // We want to scale and rotate around center of image / screen
 	// m.Translate(-c.viewportCenter()[0], -c.viewportCenter()[1])
-	m.Scale(
-		math.Pow(1.01, float64(c.ZoomFactor)),
-		math.Pow(1.01, float64(c.ZoomFactor)),
-	)
+	m.Scale(c.viewScale(), c.viewScale())
 	m.Rotate(c.Rotation)
 	m.Translate(c.viewportCenter()[0], c.viewportCenter()[1])
+	c.applyShake(&m)
 	return m
 }
 
+// applyShake shakes `m` around the viewport centre, on screen
+func (c *Camera) applyShake(m *ebiten.GeoM) {
+	if c.shake.trauma <= 0 {
+		return
+	}
+	center := c.viewportCenter()
+	m.Translate(-center[0], -center[1])
+	m.Rotate(c.shake.angle)
+	m.Translate(center[0]+c.shake.offset[0], center[1]+c.shake.offset[1])
+}
+
+// Scale return the zoom scale, 1.01^ZoomFactor multiplied by SetScale
 func (c *Camera) Scale() float64 {
-	return math.Pow(1.01, float64(c.ZoomFactor))
+	s := c.scale
+	if s == 0 {
+		s = 1
+	}
+	return s * math.Pow(1.01, float64(c.ZoomFactor))
+}
+
+// viewScale return the scale used on screen, PixelScale in pixel perfect
+// mode, otherwise Scale
+func (c *Camera) viewScale() float64 {
+	if c.pixel.enabled {
+		return float64(c.PixelScale())
+	}
+	return c.Scale()
 }
 
-// Render draw `world` image on screen
+// Render draw `world` image on screen, clipped to the viewport
 func (c *Camera) Render(screen, world *ebiten.Image) {
-	screen.DrawImage(world, &ebiten.DrawImageOptions{
-		GeoM: c.worldMatrix(),
+	if c.pixel.enabled {
+		buffer, m := c.Buffer()
+		buffer.DrawImage(world, &ebiten.DrawImageOptions{GeoM: m})
+		c.Present(screen)
+		return
+	}
+	c.Target(screen).DrawImage(world, &ebiten.DrawImageOptions{
+		GeoM: c.matrices().matrix,
 	})
 }
 
+// Target return part of `screen` inside the viewport, drawing on it is
+// clipped to the viewport, it shares coordinates with `screen` so camera
+// matrix can be used as is
+func (c *Camera) Target(screen *ebiten.Image) *ebiten.Image {
+	return screen.SubImage(c.ViewPortRect()).(*ebiten.Image)
+}
+
+// RenderViews calls `draw` for each camera with the screen clipped to its
+// viewport, e.g. split screen, or picture in picture with a smaller
+// viewport drawn last
+func RenderViews(screen *ebiten.Image, cameras []*Camera, draw func(target *ebiten.Image, cam *Camera)) {
+	for _, cam := range cameras {
+		draw(cam.Target(screen), cam)
+	}
+}
+
 func (c *Camera) ScreenToWorld(posX, posY int) (float64, float64) {
-	inverseMatrix := c.matrix
-	if inverseMatrix.IsInvertible() {
-		inverseMatrix.Invert()
-		return inverseMatrix.Apply(float64(posX), float64(posY))
+	mc := c.matrices()
+	if mc.invertible {
+		return mc.inverse.Apply(float64(posX), float64(posY))
 	} else {
 		// When scaling it can happend that matrix is not invertable
 		return math.NaN(), math.NaN()
@@ -129,29 +405,118 @@ func (c *Camera) ScreenToWorld(posX, posY int) (float64, float64) {
 }
 
 func (c *Camera) WorldToScreen(wx, wy float64) (float64, float64) {
-	sx, sy := c.matrix.Apply(wx, wy)
+	sx, sy := c.matrices().matrix.Apply(wx, wy)
 	return sx, sy
 }
 
 // WorldToScreen32 return x, y in float32, ebiten screen drawing use float32
 func (c *Camera) WorldToScreen32(wx, wy float64) (float32, float32) {
-	sx, sy := c.matrix.Apply(wx, wy)
+	sx, sy := c.matrices().matrix.Apply(wx, wy)
 	return float32(sx), float32(sy)
 }
 
 // IsPointInViewport check is a point in on screen
 func (c *Camera) IsPointInViewport(wx, wy float64) bool {
-	m := c.worldMatrix()
-	sx, sy := m.Apply(wx, wy)
-	if sx >= 0 && sx <= c.ViewPort[0] && sy >= 0 && sy <= c.ViewPort[1] {
+	sx, sy := c.matrices().matrix.Apply(wx, wy)
+	x0, y0, x1, y1 := c.viewportRect()
+	if sx >= x0 && sx <= x1 && sy >= y0 && sy <= y1 {
 		return true
 	}
 	return false
 }
 
+// VisibleQuad return the corners of the viewport in the world, top left,
+// top right, bottom right, bottom left on screen
+func (c *Camera) VisibleQuad() [4]f64.Vec2 {
+	mc := c.matrices()
+	if !mc.invertible {
+		return [4]f64.Vec2{}
+	}
+	m := mc.inverse
+	x0, y0, x1, y1 := c.viewportRect()
+	quad := [4]f64.Vec2{}
+	for i, p := range [4]f64.Vec2{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+		quad[i][0], quad[i][1] = m.Apply(p[0], p[1])
+	}
+	return quad
+}
+
+// VisibleRect return the axis aligned box around the visible area in the world
+func (c *Camera) VisibleRect() (minX, minY, maxX, maxY float64) {
+	quad := c.VisibleQuad()
+	minX, minY = quad[0][0], quad[0][1]
+	maxX, maxY = minX, minY
+	for _, p := range quad[1:] {
+		minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+		minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+	}
+	return minX, minY, maxX, maxY
+}
+
+// IsRectInViewport check if any part of world rectangle at x, y with
+// width w and height h is on screen
+func (c *Camera) IsRectInViewport(x, y, w, h float64) bool {
+	m := c.matrices().matrix
+	var quad [4]f64.Vec2
+	for i, p := range [4]f64.Vec2{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}} {
+		quad[i][0], quad[i][1] = m.Apply(p[0], p[1])
+	}
+	x0, y0, x1, y1 := c.viewportRect()
+	// separating axis test, screen axes first
+	minX, minY, maxX, maxY := quad[0][0], quad[0][1], quad[0][0], quad[0][1]
+	for _, p := range quad[1:] {
+		minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+		minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+	}
+	if maxX < x0 || minX > x1 || maxY < y0 || minY > y1 {
+		return false
+	}
+	// then the edges of the rectangle on screen
+	screen := [4]f64.Vec2{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+	for i := 0; i < 2; i++ {
+		axis := Vector{quad[i+1][0] - quad[i][0], quad[i+1][1] - quad[i][1]}.Perp()
+		qMin, qMax := projectQuad(quad, axis)
+		sMin, sMax := projectQuad(screen, axis)
+		if qMax < sMin || sMax < qMin {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCircleInViewport check if any part of world circle at x, y with
+// radius r is on screen
+func (c *Camera) IsCircleInViewport(x, y, r float64) bool {
+	sx, sy := c.matrices().matrix.Apply(x, y)
+	sr := r * c.viewScale()
+	x0, y0, x1, y1 := c.viewportRect()
+	dx := sx - math.Max(x0, math.Min(sx, x1))
+	dy := sy - math.Max(y0, math.Min(sy, y1))
+	return dx*dx+dy*dy <= sr*sr
+}
+
+// projectQuad return min and max of `quad` projected on `axis`
+func projectQuad(quad [4]f64.Vec2, axis Vector) (float64, float64) {
+	min := math.Inf(1)
+	max := math.Inf(-1)
+	for _, p := range quad {
+		d := axis.Dot(Vector{p[0], p[1]})
+		min, max = math.Min(min, d), math.Max(max, d)
+	}
+	return min, max
+}
+
+// viewportRect return the viewport on screen
+func (c *Camera) viewportRect() (x0, y0, x1, y1 float64) {
+	return c.Offset[0], c.Offset[1], c.Offset[0] + c.ViewPort[0], c.Offset[1] + c.ViewPort[1]
+}
+
 func (c *Camera) Reset() {
 	c.Position[0] = 0
 	c.Position[1] = 0
 	c.Rotation = 0
 	c.ZoomFactor = 0
+	c.scale = 1
+	c.clampScale()
+	c.clampPosition()
 }
@@ -5,24 +5,45 @@ import (
 	"math"
 )
 
+// default clip planes of NewCamera3D
+const (
+	defaultNear = 5.
+	defaultFar  = 500.
+)
+
+// Projection of Camera3D
+type Projection int
+
+const (
+	ProjectionPerspective  Projection = iota // further objects are smaller
+	ProjectionOrthographic                   // no perspective, e.g. isometric or CAD views
+)
+
 type Camera3D struct {
 	pos    Vector3
 	lookAt Vector3
 	fov    float64 // degree, field of view horizonatlly
 	w      float64 // screen size in pixels
 	h      float64
+	near   float64 // near and far clip planes, distance from Camera3D
+	far    float64
+
+	projection Projection
+	viewHeight float64 // orthographic, world units visible vertically
 
 	viewMatrix       []float64 // Camera3D matrix
 	projectionMatrix []float64 // perspective matrix
 	viewportMatrix   []float64
 	mvp              []float64 // model-view-perspective
 	combineMatrix    []float64 // viewport * projection * view
+	frustum          [6]Plane  // visible volume, from mvp
 
 	changed bool // track if Camera3D requires update
 }
 
 func NewCamera3D(pos, lookAt Vector3, fov, w, h float64) *Camera3D {
-	cam := &Camera3D{pos: pos, lookAt: lookAt, fov: fov, w: w, h: h, changed: true}
+	cam := &Camera3D{pos: pos, lookAt: lookAt, fov: fov, w: w, h: h,
+		near: defaultNear, far: defaultFar, changed: true}
 	cam.Update()
 	return cam
 }
@@ -92,12 +113,58 @@ func (cam *Camera3D) FOV(delta float64) {
 	cam.changed = true
 }
 
+// SetNearFar set the near and far clip planes, distance from the Camera3D,
+// need to call Update() the Camera3D manually
+func (cam *Camera3D) SetNearFar(near, far float64) {
+	cam.near = near
+	cam.far = far
+	cam.changed = true
+}
+
+// NearFar return the near and far clip planes
+func (cam *Camera3D) NearFar() (float64, float64) {
+	return cam.near, cam.far
+}
+
+// SetProjection switch between perspective and orthographic projection,
+// need to call Update() the Camera3D manually
+func (cam *Camera3D) SetProjection(p Projection) {
+	cam.projection = p
+	cam.changed = true
+}
+
+// Projection return the projection in use
+func (cam *Camera3D) Projection() Projection {
+	return cam.projection
+}
+
+// SetViewHeight set world units visible vertically in orthographic
+// projection, 0 matches the perspective view at the look at point,
+// need to call Update() the Camera3D manually
+func (cam *Camera3D) SetViewHeight(h float64) {
+	cam.viewHeight = h
+	cam.changed = true
+}
+
+// ViewHeight return world units visible vertically in orthographic projection
+func (cam *Camera3D) ViewHeight() float64 {
+	if cam.viewHeight > 0 {
+		return cam.viewHeight
+	}
+	// same size as perspective at the look at point
+	fovX := cam.fov / 180. * math.Pi
+	fovY := 2. * math.Atan(math.Tan(fovX/2.)/(cam.w/cam.h))
+	return 2. * cam.lookAt.Sub(cam.pos).Length() * math.Tan(fovY/2.)
+}
+
 func (cam *Camera3D) UpdateCamera3D(pos, lookAt Vector3, fov, w, h float64) {
 	fovX := fov / 180. * math.Pi
 	aspectRatio := w / h
 	fovY := 2. * math.Atan(math.Tan(fovX/2.)/aspectRatio) // radian
-	near := 5.
-	far := 500.
+	near, far := cam.near, cam.far
+	if far <= near || (near <= 0 && cam.projection == ProjectionPerspective) {
+		near, far = defaultNear, defaultFar
+	}
 	up := Vector3{0, 1, 0}.Normalize()
 	// zAxis := pos.Sub(lookAt).Normalize()
 	zAxis := lookAt.Sub(pos).Normalize()
@@ -120,13 +187,24 @@ func (cam *Camera3D) UpdateCamera3D(pos, lookAt Vector3, fov, w, h float64) {
 		zAxis.X, zAxis.Y, zAxis.Z, translate.Z,
 		0, 0, 0, 1,
 	}
-	cam.projectionMatrix = []float64{
-		1. / (aspectRatio * math.Tan(fovY/2.)), 0, 0, 0,
-		0, 1. / math.Tan(fovY/2.0), 0, 0,
-		// 0, 0, -(far + near) / (far - near), -(2. * far * near) / (far - near),
-		// 0, 0, -1, 0,
-		0, 0, (far) / (far - near), (-near * far) / (far - near),
-		0, 0, 1, 0,
+	if cam.projection == ProjectionOrthographic {
+		viewH := cam.ViewHeight()
+		// z between 0 at near and 1 at far, w is 1
+		cam.projectionMatrix = []float64{
+			2. / (viewH * aspectRatio), 0, 0, 0,
+			0, 2. / viewH, 0, 0,
+			0, 0, 1. / (far - near), -near / (far - near),
+			0, 0, 0, 1,
+		}
+	} else {
+		cam.projectionMatrix = []float64{
+			1. / (aspectRatio * math.Tan(fovY/2.)), 0, 0, 0,
+			0, 1. / math.Tan(fovY/2.0), 0, 0,
+			// 0, 0, -(far + near) / (far - near), -(2. * far * near) / (far - near),
+			// 0, 0, -1, 0,
+			0, 0, (far) / (far - near), (-near * far) / (far - near),
+			0, 0, 1, 0,
+		}
 	}
 	vpX := 0. // origin of viewport, in screen coordinate
 	vpY := 0.
@@ -143,15 +221,10 @@ func (cam *Camera3D) UpdateCamera3D(pos, lookAt Vector3, fov, w, h float64) {
 
 func (cam *Camera3D) updateCombineMatrix() {
 	// ScreenPos = ViewportMatrix * ProjectionMatrix * ViewMatrix * ModelMatrix * WorldPos
-	// projection * view
-	p := cam.projectionMatrix
-	v := cam.viewMatrix
-	cam.mvp = []float64{
-		p[0] * v[0], p[0] * v[1], p[0] * v[2], p[0] * v[3],
-		p[5] * v[4], p[5] * v[5], p[5] * v[6], p[5] * v[7],
-		p[10] * v[8], p[10] * v[9], p[10] * v[10], p[10]*v[11] + p[11]*v[15],
-		p[14] * v[8], p[14] * v[9], p[14] * v[10], p[14] * v[11],
-	}
+	// projection * view, full multiplication as orthographic projection
+	// uses other elements than perspective
+	cam.mvp = MatrixMultiplication(cam.projectionMatrix, cam.viewMatrix)
+	cam.updateFrustum()
 
 	// combineMatrix = viewport * temp (mvp matrix)
 	cam.viewportMultMVP()
@@ -162,7 +235,7 @@ func (cam *Camera3D) PosToScreen(p Vector3) []float32 {
 	c := cam.combineMatrix
 	w := c[12]*p.X + c[13]*p.Y + c[14]*p.Z + c[15]
 	// divide by w for perspective division, when w is -ve, the point is
-	// behind the Camera3D
+	// behind the Camera3D, w is 1 in orthographic projection, use z < 0
 	x := (c[0]*p.X + c[1]*p.Y + c[2]*p.Z + c[3]) / w
 	y := (c[4]*p.X + c[5]*p.Y + c[6]*p.Z + c[7]) / w
 	// z, typically between 0 and 1, encodes how deep this point is in the viewport
@@ -176,7 +249,7 @@ func (cam *Camera3D) WorldToScreen(p []float64) []float64 {
 	c := cam.combineMatrix
 	w := c[12]*p[0] + c[13]*p[1] + c[14]*p[2] + c[15]
 	// divide by w for perspective division, when w is -ve, the point is
-	// behind the Camera3D
+	// behind the Camera3D, w is 1 in orthographic projection, use z < 0
 	x := (c[0]*p[0] + c[1]*p[1] + c[2]*p[2] + c[3]) / w
 	y := (c[4]*p[0] + c[5]*p[1] + c[6]*p[2] + c[7]) / w
 	// z, typically between 0 and 1, encodes how deep this point is in the viewport
@@ -0,0 +1,132 @@
+package dango
+
+// Plane is the points p where Normal.Dot(p) + D == 0, Normal is unit length
+type Plane struct {
+	Normal Vector3
+	D      float64
+}
+
+// Distance return signed distance from the plane to `p`, positive on the
+// side the normal points to
+func (pl Plane) Distance(p Vector3) float64 {
+	return pl.Normal.Dot(p) + pl.D
+}
+
+// Visibility of an object in the Camera3D frustum
+type Visibility int
+
+const (
+	VisibilityOutside      Visibility = iota // not visible, skip drawing
+	VisibilityIntersecting                   // partly visible, crosses a frustum plane
+	VisibilityInside                         // completely visible
+)
+
+func (v Visibility) String() string {
+	switch v {
+	case VisibilityOutside:
+		return "outside"
+	case VisibilityIntersecting:
+		return "intersecting"
+	case VisibilityInside:
+		return "inside"
+	}
+	return "unknown"
+}
+
+// Frustum planes in the order returned by FrustumPlanes
+const (
+	FrustumLeft = iota
+	FrustumRight
+	FrustumBottom
+	FrustumTop
+	FrustumNear
+	FrustumFar
+)
+
+// FrustumPlanes return the left, right, bottom, top, near and far planes of
+// the visible volume, normals point inside, uses matrix from Update()
+func (cam *Camera3D) FrustumPlanes() [6]Plane {
+	return cam.frustum
+}
+
+// updateFrustum extracts the frustum planes from the mvp matrix
+func (cam *Camera3D) updateFrustum() {
+	m := cam.mvp
+	row := func(i int) [4]float64 {
+		return [4]float64{m[4*i], m[4*i+1], m[4*i+2], m[4*i+3]}
+	}
+	r0, r1, r2, r3 := row(0), row(1), row(2), row(3)
+	add := func(a, b [4]float64, s float64) [4]float64 {
+		return [4]float64{a[0] + s*b[0], a[1] + s*b[1], a[2] + s*b[2], a[3] + s*b[3]}
+	}
+	// clip space is -w <= x <= w, -w <= y <= w, 0 <= z <= w
+	rows := [6][4]float64{
+		add(r3, r0, 1),
+		add(r3, r0, -1),
+		add(r3, r1, 1),
+		add(r3, r1, -1),
+		r2,
+		add(r3, r2, -1),
+	}
+	for i, r := range rows {
+		n := Vector3{r[0], r[1], r[2]}
+		l := n.Length()
+		if l == 0 {
+			cam.frustum[i] = Plane{}
+			continue
+		}
+		cam.frustum[i] = Plane{Normal: n.Mult(1 / l), D: r[3] / l}
+	}
+}
+
+// PointInFrustum return true when `p` is visible
+func (cam *Camera3D) PointInFrustum(p Vector3) bool {
+	for _, pl := range cam.frustum {
+		if pl.Distance(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SphereInFrustum return visibility of sphere at `center` with radius `r`,
+// may return intersecting for spheres just outside a frustum corner
+func (cam *Camera3D) SphereInFrustum(center Vector3, r float64) Visibility {
+	result := VisibilityInside
+	for _, pl := range cam.frustum {
+		d := pl.Distance(center)
+		if d < -r {
+			return VisibilityOutside
+		}
+		if d < r {
+			result = VisibilityIntersecting
+		}
+	}
+	return result
+}
+
+// BoxInFrustum return visibility of axis aligned box from `min` to `max`,
+// may return intersecting for boxes just outside a frustum corner
+func (cam *Camera3D) BoxInFrustum(min, max Vector3) Visibility {
+	result := VisibilityInside
+	for _, pl := range cam.frustum {
+		// corners furthest along and against the normal
+		pos, neg := min, max
+		if pl.Normal.X >= 0 {
+			pos.X, neg.X = max.X, min.X
+		}
+		if pl.Normal.Y >= 0 {
+			pos.Y, neg.Y = max.Y, min.Y
+		}
+		if pl.Normal.Z >= 0 {
+			pos.Z, neg.Z = max.Z, min.Z
+		}
+		if pl.Distance(pos) < 0 {
+			return VisibilityOutside
+		}
+		if pl.Distance(neg) < 0 {
+			result = VisibilityIntersecting
+		}
+	}
+	return result
+}
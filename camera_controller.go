@@ -0,0 +1,243 @@
+package dango
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/rtpchan/dango/input"
+)
+
+// CameraControls binds input to camera movement, zero values and empty
+// bindings disable a control
+type CameraControls struct {
+	DragButtons  []ebiten.MouseButton // drag to pan, e.g. middle and right button
+	TouchDrag    bool                 // one finger drag to pan
+	Pinch        bool                 // two finger pinch to zoom
+	PinchRotate  bool                 // two finger twist to rotate
+	WheelZoom    float64              // scale multiplier per wheel notch, e.g. 1.1
+	KeyUp        []ebiten.Key
+	KeyDown      []ebiten.Key
+	KeyLeft      []ebiten.Key
+	KeyRight     []ebiten.Key
+	KeySpeed     float64 // screen pixels per second
+	KeyZoomIn    []ebiten.Key
+	KeyZoomOut   []ebiten.Key
+	KeyZoomSpeed float64 // scale multiplier per second, e.g. 2
+	Friction     float64 // fling slows by e^-Friction per second, 0 for no fling
+}
+
+// DefaultCameraControls is used by NewCameraController
+var DefaultCameraControls = CameraControls{
+	DragButtons:  []ebiten.MouseButton{ebiten.MouseButtonMiddle, ebiten.MouseButtonRight},
+	TouchDrag:    true,
+	Pinch:        true,
+	PinchRotate:  true,
+	WheelZoom:    1.1,
+	KeyUp:        []ebiten.Key{ebiten.KeyW, ebiten.KeyArrowUp},
+	KeyDown:      []ebiten.Key{ebiten.KeyS, ebiten.KeyArrowDown},
+	KeyLeft:      []ebiten.Key{ebiten.KeyA, ebiten.KeyArrowLeft},
+	KeyRight:     []ebiten.Key{ebiten.KeyD, ebiten.KeyArrowRight},
+	KeySpeed:     600,
+	KeyZoomIn:    []ebiten.Key{ebiten.KeyE, ebiten.KeyEqual},
+	KeyZoomOut:   []ebiten.Key{ebiten.KeyQ, ebiten.KeyMinus},
+	KeyZoomSpeed: 2,
+	Friction:     5,
+}
+
+// CameraController moves a Camera with mouse drag, wheel, touch and keys,
+// call Update once per frame
+type CameraController struct {
+	Camera   *Camera
+	Controls CameraControls
+	Source   input.Source // nil reads input.CurrentSource()
+
+	gesture  gesture
+	last     Vector // cursor or touch position last frame, on screen
+	velocity Vector // drag speed on screen, pixels per second
+	touches  []ebiten.TouchID
+	pinchIDs [2]ebiten.TouchID
+	pinch    [2]Vector // touch positions last frame
+}
+
+type gesture int
+
+const (
+	gestureNone gesture = iota
+	gestureDrag
+	gesturePinch
+)
+
+// NewCameraController create a controller for `cam` with DefaultCameraControls
+func NewCameraController(cam *Camera) *CameraController {
+	return &CameraController{Camera: cam, Controls: DefaultCameraControls}
+}
+
+// Stop ends drag and fling
+func (cc *CameraController) Stop() {
+	cc.gesture = gestureNone
+	cc.velocity = Vector{}
+}
+
+// Update moves the camera by the input of this frame, `dt` seconds since
+// the last call
+func (cc *CameraController) Update(dt float64) {
+	src := cc.Source
+	if src == nil {
+		src = input.CurrentSource()
+	}
+	cc.touches = src.AppendTouchIDs(cc.touches[:0])
+
+	switch {
+	case len(cc.touches) >= 2 && (cc.Controls.Pinch || cc.Controls.PinchRotate):
+		cc.updatePinch(src, dt)
+	case cc.dragHeld(src):
+		cc.updateDrag(src, dt)
+	default:
+		cc.gesture = gestureNone
+		cc.fling(dt)
+	}
+	cc.updateWheel(src)
+	cc.updateKeys(src, dt)
+}
+
+// dragHeld return true when a drag button or one finger is down
+func (cc *CameraController) dragHeld(src input.Source) bool {
+	if cc.Controls.TouchDrag && len(cc.touches) == 1 {
+		return true
+	}
+	for _, b := range cc.Controls.DragButtons {
+		if src.IsMouseButtonPressed(b) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cc *CameraController) updateDrag(src input.Source, dt float64) {
+	var x, y int
+	if cc.Controls.TouchDrag && len(cc.touches) == 1 {
+		x, y = src.TouchPosition(cc.touches[0])
+	} else {
+		x, y = src.CursorPosition()
+	}
+	p := Vector{float64(x), float64(y)}
+	if cc.gesture != gestureDrag {
+		cc.gesture = gestureDrag
+		cc.last = p
+		cc.velocity = Vector{}
+		return
+	}
+	delta := p.Sub(cc.last)
+	cc.last = p
+	cc.panScreen(delta)
+	if dt > 0 {
+		cc.velocity = delta.Mult(1 / dt)
+	}
+}
+
+// fling keeps the camera moving after a drag is released
+func (cc *CameraController) fling(dt float64) {
+	if cc.Controls.Friction <= 0 || cc.velocity.LengthSq() < 1 {
+		cc.velocity = Vector{}
+		return
+	}
+	cc.panScreen(cc.velocity.Mult(dt))
+	cc.velocity = cc.velocity.Mult(math.Exp(-cc.Controls.Friction * dt))
+}
+
+// panScreen moves the world by `delta` screen pixels, respecting rotation
+// and zoom, i.e. the world follows the cursor
+func (cc *CameraController) panScreen(delta Vector) {
+	s := cc.Camera.viewScale()
+	cc.Camera.Pan(-delta.X/s, -delta.Y/s)
+}
+
+func (cc *CameraController) updatePinch(src input.Source, dt float64) {
+	var p [2]Vector
+	ids := [2]ebiten.TouchID{cc.touches[0], cc.touches[1]}
+	for i, id := range ids {
+		x, y := src.TouchPosition(id)
+		p[i] = Vector{float64(x), float64(y)}
+	}
+	if cc.gesture != gesturePinch || ids != cc.pinchIDs {
+		// new pinch, or the fingers changed
+		cc.gesture = gesturePinch
+		cc.pinchIDs = ids
+		cc.velocity = Vector{}
+		cc.pinch = p
+		return
+	}
+	prev := cc.pinch
+	cc.pinch = p
+	c := cc.Camera
+	oldMid := prev[0].Add(prev[1]).Mult(0.5)
+	mid := p[0].Add(p[1]).Mult(0.5)
+	mc := c.matrices()
+	if !mc.invertible {
+		return
+	}
+	wx, wy := mc.inverse.Apply(oldMid.X, oldMid.Y)
+
+	before, after := prev[1].Sub(prev[0]), p[1].Sub(p[0])
+	if cc.Controls.Pinch && before.Length() > 0 && after.Length() > 0 {
+		c.ZoomBy(after.Length() / before.Length())
+	}
+	if cc.Controls.PinchRotate && before.LengthSq() > 0 && after.LengthSq() > 0 {
+		c.Rotate(after.ToAngle() - before.ToAngle())
+	}
+	// world point under the old midpoint moves with the fingers
+	c.placeAt(wx, wy, mid.X, mid.Y)
+	if dt > 0 {
+		cc.velocity = mid.Sub(oldMid).Mult(1 / dt)
+	}
+}
+
+func (cc *CameraController) updateWheel(src input.Source) {
+	if cc.Controls.WheelZoom <= 0 {
+		return
+	}
+	_, wy := src.Wheel()
+	if wy == 0 {
+		return
+	}
+	x, y := src.CursorPosition()
+	cc.Camera.ZoomAt(float64(x), float64(y), math.Pow(cc.Controls.WheelZoom, wy))
+}
+
+func (cc *CameraController) updateKeys(src input.Source, dt float64) {
+	ctl := cc.Controls
+	dir := Vector{}
+	if anyKeyPressed(src, ctl.KeyLeft) {
+		dir.X--
+	}
+	if anyKeyPressed(src, ctl.KeyRight) {
+		dir.X++
+	}
+	if anyKeyPressed(src, ctl.KeyUp) {
+		dir.Y--
+	}
+	if anyKeyPressed(src, ctl.KeyDown) {
+		dir.Y++
+	}
+	if dir.LengthSq() > 0 && ctl.KeySpeed > 0 {
+		// keys move the view, the opposite of dragging the world
+		cc.panScreen(dir.Normalize().Mult(-ctl.KeySpeed * dt))
+	}
+	if ctl.KeyZoomSpeed > 0 {
+		if anyKeyPressed(src, ctl.KeyZoomIn) {
+			cc.Camera.ZoomBy(math.Pow(ctl.KeyZoomSpeed, dt))
+		}
+		if anyKeyPressed(src, ctl.KeyZoomOut) {
+			cc.Camera.ZoomBy(math.Pow(ctl.KeyZoomSpeed, -dt))
+		}
+	}
+}
+
+func anyKeyPressed(src input.Source, keys []ebiten.Key) bool {
+	for _, k := range keys {
+		if src.IsKeyPressed(k) {
+			return true
+		}
+	}
+	return false
+}
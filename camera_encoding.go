@@ -0,0 +1,219 @@
+package dango
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"golang.org/x/image/math/f64"
+)
+
+var (
+	// value receivers, so cameras held by value in a struct are encoded too
+	_ json.Marshaler             = Camera{}
+	_ json.Unmarshaler           = (*Camera)(nil)
+	_ encoding.BinaryMarshaler   = Camera{}
+	_ encoding.BinaryUnmarshaler = (*Camera)(nil)
+	_ json.Marshaler             = Camera3D{}
+	_ json.Unmarshaler           = (*Camera3D)(nil)
+	_ encoding.BinaryMarshaler   = Camera3D{}
+	_ encoding.BinaryUnmarshaler = (*Camera3D)(nil)
+)
+
+// ErrCameraVersion is returned when decoding a camera saved by a newer format
+var ErrCameraVersion = errors.New("unsupported camera version")
+
+// cameraVersion of the encoding written by Marshal methods. New fields are
+// added at the end and are optional, older data decodes with them unset,
+// the version only changes when existing fields change meaning.
+const cameraVersion = 1
+
+type cameraJSON struct {
+	Version    int      `json:"version"`
+	Position   f64.Vec2 `json:"position"`
+	ZoomFactor int      `json:"zoomFactor,omitempty"`
+	Scale      float64  `json:"scale,omitempty"`
+	Rotation   float64  `json:"rotation,omitempty"`
+	ViewPort   f64.Vec2 `json:"viewPort"`
+	Offset     f64.Vec2 `json:"offset"`
+}
+
+// MarshalJSON saves position, zoom, rotation and viewport, bounds, limits
+// and animations are not saved
+func (c Camera) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cameraJSON{
+		Version:    cameraVersion,
+		Position:   c.Position,
+		ZoomFactor: c.ZoomFactor,
+		Scale:      c.scale,
+		Rotation:   c.Rotation,
+		ViewPort:   c.ViewPort,
+		Offset:     c.Offset,
+	})
+}
+
+// UnmarshalJSON restores a view saved by MarshalJSON, the position and scale
+// are kept inside the camera's bounds and zoom limits
+func (c *Camera) UnmarshalJSON(data []byte) error {
+	var v cameraJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := checkCameraVersion(v.Version); err != nil {
+		return err
+	}
+	c.Position = v.Position
+	c.ZoomFactor = v.ZoomFactor
+	c.scale = v.Scale
+	c.Rotation = v.Rotation
+	c.ViewPort = v.ViewPort
+	c.Offset = v.Offset
+	c.clampScale()
+	c.clampPosition()
+	return nil
+}
+
+// MarshalBinary saves the same fields as MarshalJSON
+func (c Camera) MarshalBinary() ([]byte, error) {
+	return marshalCameraFields([]float64{
+		c.Position[0], c.Position[1],
+		float64(c.ZoomFactor), c.scale, c.Rotation,
+		c.ViewPort[0], c.ViewPort[1],
+		c.Offset[0], c.Offset[1],
+	}), nil
+}
+
+// UnmarshalBinary restores a view saved by MarshalBinary
+func (c *Camera) UnmarshalBinary(data []byte) error {
+	f, err := unmarshalCameraFields(data, 9)
+	if err != nil {
+		return err
+	}
+	c.Position = f64.Vec2{f[0], f[1]}
+	c.ZoomFactor = int(f[2])
+	c.scale = f[3]
+	c.Rotation = f[4]
+	c.ViewPort = f64.Vec2{f[5], f[6]}
+	c.Offset = f64.Vec2{f[7], f[8]}
+	c.clampScale()
+	c.clampPosition()
+	return nil
+}
+
+type camera3DJSON struct {
+	Version int     `json:"version"`
+	Pos     Vector3 `json:"pos"`
+	LookAt  Vector3 `json:"lookAt"`
+	FOV     float64 `json:"fov"`
+	W       float64 `json:"w"`
+	H       float64 `json:"h"`
+	Near    float64 `json:"near"`
+	Far     float64 `json:"far"`
+
+	Projection Projection `json:"projection,omitempty"`
+	ViewHeight float64    `json:"viewHeight,omitempty"`
+}
+
+// MarshalJSON saves position, look at, fov, screen size, clip planes and
+// projection
+func (cam Camera3D) MarshalJSON() ([]byte, error) {
+	return json.Marshal(camera3DJSON{
+		Version:    cameraVersion,
+		Pos:        cam.pos,
+		LookAt:     cam.lookAt,
+		FOV:        cam.fov,
+		W:          cam.w,
+		H:          cam.h,
+		Near:       cam.near,
+		Far:        cam.far,
+		Projection: cam.projection,
+		ViewHeight: cam.viewHeight,
+	})
+}
+
+// UnmarshalJSON restores a Camera3D saved by MarshalJSON and updates it
+func (cam *Camera3D) UnmarshalJSON(data []byte) error {
+	var v camera3DJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := checkCameraVersion(v.Version); err != nil {
+		return err
+	}
+	cam.pos, cam.lookAt = v.Pos, v.LookAt
+	cam.fov, cam.w, cam.h = v.FOV, v.W, v.H
+	cam.near, cam.far = v.Near, v.Far
+	cam.projection, cam.viewHeight = v.Projection, v.ViewHeight
+	cam.changed = true
+	cam.Update()
+	return nil
+}
+
+// MarshalBinary saves the same fields as MarshalJSON
+func (cam Camera3D) MarshalBinary() ([]byte, error) {
+	return marshalCameraFields([]float64{
+		cam.pos.X, cam.pos.Y, cam.pos.Z,
+		cam.lookAt.X, cam.lookAt.Y, cam.lookAt.Z,
+		cam.fov, cam.w, cam.h,
+		cam.near, cam.far,
+		float64(cam.projection), cam.viewHeight,
+	}), nil
+}
+
+// UnmarshalBinary restores a Camera3D saved by MarshalBinary and updates it
+func (cam *Camera3D) UnmarshalBinary(data []byte) error {
+	f, err := unmarshalCameraFields(data, 13)
+	if err != nil {
+		return err
+	}
+	cam.pos = Vector3{f[0], f[1], f[2]}
+	cam.lookAt = Vector3{f[3], f[4], f[5]}
+	cam.fov, cam.w, cam.h = f[6], f[7], f[8]
+	cam.near, cam.far = f[9], f[10]
+	cam.projection, cam.viewHeight = Projection(f[11]), f[12]
+	cam.changed = true
+	cam.Update()
+	return nil
+}
+
+func checkCameraVersion(v int) error {
+	if v < 1 || v > cameraVersion {
+		return fmt.Errorf("%w %d", ErrCameraVersion, v)
+	}
+	return nil
+}
+
+// marshalCameraFields writes version byte, number of fields byte, then
+// fields as little endian float64
+func marshalCameraFields(fields []float64) []byte {
+	b := make([]byte, 2, 2+8*len(fields))
+	b[0] = cameraVersion
+	b[1] = byte(len(fields))
+	for _, f := range fields {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(f))
+	}
+	return b
+}
+
+// unmarshalCameraFields return `n` fields, fields missing from older data
+// are 0, extra fields from newer data are ignored
+func unmarshalCameraFields(data []byte, n int) ([]float64, error) {
+	if len(data) < 2 {
+		return nil, errors.New("camera data too short")
+	}
+	if err := checkCameraVersion(int(data[0])); err != nil {
+		return nil, err
+	}
+	count := int(data[1])
+	if len(data) < 2+8*count {
+		return nil, errors.New("camera data too short")
+	}
+	fields := make([]float64, n)
+	for i := 0; i < n && i < count; i++ {
+		fields[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[2+8*i:]))
+	}
+	return fields, nil
+}
@@ -0,0 +1,126 @@
+package dango
+
+import (
+	"math"
+
+	"golang.org/x/image/math/f64"
+)
+
+// FollowSmoothing is how Camera.Follow moves towards the target
+type FollowSmoothing int
+
+const (
+	FollowSnap             FollowSmoothing = iota // move to the target immediately
+	FollowExponential                             // exponential decay towards the target
+	FollowCriticallyDamped                        // spring without overshoot, eases in and out
+)
+
+// CameraFollow configures Camera.Follow
+type CameraFollow struct {
+	DeadZone     f64.Vec2 // width, height in screen pixels at viewport centre, target moves freely inside
+	Smoothing    FollowSmoothing
+	SmoothTime   float64 // seconds, roughly the time to catch up with the target
+	LookAhead    float64 // seconds of target velocity to look ahead
+	MaxLookAhead float64 // maximum look ahead distance in world units, 0 for no limit
+	LockX        bool    // camera does not move along world x axis
+	LockY        bool    // camera does not move along world y axis
+}
+
+type followState struct {
+	config     CameraFollow
+	velocity   f64.Vec2 // camera velocity, for critical damping
+	lastTarget f64.Vec2
+	hasTarget  bool
+}
+
+// SetFollow set how the camera follows a target with Follow
+func (c *Camera) SetFollow(f CameraFollow) {
+	c.follow.config = f
+}
+
+// ResetFollow forgets the target velocity, e.g. after the target teleports
+func (c *Camera) ResetFollow() {
+	c.follow.velocity = f64.Vec2{}
+	c.follow.hasTarget = false
+}
+
+// Follow moves the camera towards target position x, y in the world,
+// call once per frame with `dt` seconds since the last call,
+// e.g. 1/ebiten.TPS()
+func (c *Camera) Follow(x, y, dt float64) {
+	f := &c.follow
+	cfg := f.config
+	target := f64.Vec2{x, y}
+
+	// look ahead in the direction the target is moving
+	goal := target
+	if f.hasTarget && dt > 0 && cfg.LookAhead > 0 {
+		ahead := Vector{(x - f.lastTarget[0]) / dt, (y - f.lastTarget[1]) / dt}.Mult(cfg.LookAhead)
+		if cfg.MaxLookAhead > 0 {
+			ahead = ahead.Clamp(cfg.MaxLookAhead)
+		}
+		goal = f64.Vec2{x + ahead.X, y + ahead.Y}
+	}
+	f.lastTarget = target
+	f.hasTarget = true
+
+	// only move the camera by how far the goal is outside the dead zone,
+	// dead zone is measured on screen, i.e. after scale and rotation
+	scale := c.viewScale()
+	offset := Vector{goal[0] - c.Position[0], goal[1] - c.Position[1]}
+	local := offset.Rotate(ForAngle(c.Rotation)).Mult(scale)
+	excess := Vector{
+		deadZoneExcess(local.X, cfg.DeadZone[0]/2),
+		deadZoneExcess(local.Y, cfg.DeadZone[1]/2),
+	}
+	move := excess.Unrotate(ForAngle(c.Rotation)).Mult(1 / scale)
+	desired := f64.Vec2{c.Position[0] + move.X, c.Position[1] + move.Y}
+
+	pos := c.Position
+	switch {
+	case cfg.Smoothing == FollowSnap || cfg.SmoothTime <= 0 || dt <= 0:
+		pos = desired
+		f.velocity = f64.Vec2{}
+	case cfg.Smoothing == FollowExponential:
+		t := 1 - math.Exp(-dt/cfg.SmoothTime)
+		pos[0] = Lerp(pos[0], desired[0], t)
+		pos[1] = Lerp(pos[1], desired[1], t)
+	case cfg.Smoothing == FollowCriticallyDamped:
+		pos[0], f.velocity[0] = smoothDamp(pos[0], desired[0], f.velocity[0], cfg.SmoothTime, dt)
+		pos[1], f.velocity[1] = smoothDamp(pos[1], desired[1], f.velocity[1], cfg.SmoothTime, dt)
+	}
+
+	if cfg.LockX {
+		pos[0] = c.Position[0]
+		f.velocity[0] = 0
+	}
+	if cfg.LockY {
+		pos[1] = c.Position[1]
+		f.velocity[1] = 0
+	}
+	c.SetPosition(pos[0], pos[1])
+}
+
+// deadZoneExcess return how far `v` is beyond -half to half
+func deadZoneExcess(v, half float64) float64 {
+	if v > half {
+		return v - half
+	}
+	if v < -half {
+		return v + half
+	}
+	return 0
+}
+
+// smoothDamp moves `current` to `target` as a critically damped spring,
+// return new position and velocity,
+// from Game Programming Gems 4, chapter 1.10
+func smoothDamp(current, target, velocity, smoothTime, dt float64) (float64, float64) {
+	omega := 2 / smoothTime
+	x := omega * dt
+	exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+	change := current - target
+	temp := (velocity + omega*change) * dt
+	velocity = (velocity - omega*temp) * exp
+	return target + (change+temp)*exp, velocity
+}
@@ -0,0 +1,93 @@
+package dango
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type pixelState struct {
+	enabled bool
+	buffer  *ebiten.Image
+}
+
+// SetPixelPerfect turns pixel perfect mode on or off. In pixel perfect mode
+// the world is drawn 1:1 to a low resolution buffer with the camera snapped
+// to whole texels, the buffer is then upscaled by PixelScale on screen, and
+// the sub-texel part of the position is applied only at the upscale, so
+// pixel art does not shimmer when the camera moves.
+func (c *Camera) SetPixelPerfect(on bool) {
+	c.pixel.enabled = on
+	if !on && c.pixel.buffer != nil {
+		c.pixel.buffer.Deallocate()
+		c.pixel.buffer = nil
+	}
+	c.clampPosition()
+}
+
+// PixelPerfect return true in pixel perfect mode
+func (c *Camera) PixelPerfect() bool {
+	return c.pixel.enabled
+}
+
+// PixelScale return the integer upscale of pixel perfect mode, Scale
+// rounded to the nearest whole number, at least 1
+func (c *Camera) PixelScale() int {
+	return int(math.Max(1, math.Round(c.Scale())))
+}
+
+// Buffer return the cleared low resolution buffer, and the matrix to draw
+// the world on it, call Present when done, e.g.
+//
+//	buffer, m := cam.Buffer()
+//	op.GeoM.Concat(m)
+//	buffer.DrawImage(sprite, op)
+//	cam.Present(screen)
+func (c *Camera) Buffer() (*ebiten.Image, ebiten.GeoM) {
+	m, _, w, h := c.pixelMatrices()
+	b := c.pixel.buffer
+	if b == nil || b.Bounds().Dx() != w || b.Bounds().Dy() != h {
+		if b != nil {
+			b.Deallocate()
+		}
+		b = ebiten.NewImage(w, h)
+		c.pixel.buffer = b
+	}
+	b.Clear()
+	return b, m
+}
+
+// Present draws the buffer upscaled on `screen`, clipped to the viewport
+func (c *Camera) Present(screen *ebiten.Image) {
+	if c.pixel.buffer == nil {
+		return
+	}
+	_, up, _, _ := c.pixelMatrices()
+	op := &ebiten.DrawImageOptions{GeoM: up}
+	op.Filter = ebiten.FilterNearest
+	c.Target(screen).DrawImage(c.pixel.buffer, op)
+}
+
+// pixelMatrices return the world to buffer matrix, the buffer to screen
+// matrix, and the buffer size. Together they are the same as the camera
+// matrix with scale PixelScale, so ScreenToWorld and WorldToScreen agree
+// with what is drawn.
+func (c *Camera) pixelMatrices() (world, up ebiten.GeoM, w, h int) {
+	u := float64(c.PixelScale())
+	// buffer centre, with a texel of margin for the sub-texel shift
+	bx := math.Ceil(c.ViewPort[0]/u/2) + 1
+	by := math.Ceil(c.ViewPort[1]/u/2) + 1
+
+	// camera position on the buffer axes, snapped to whole texels
+	q := Vector{c.Position[0], c.Position[1]}.Rotate(ForAngle(c.Rotation))
+	qx, qy := math.Floor(q.X), math.Floor(q.Y)
+
+	world.Rotate(c.Rotation)
+	world.Translate(bx-qx, by-qy)
+
+	center := c.viewportCenter()
+	up.Scale(u, u)
+	up.Translate(center[0]-u*(bx+q.X-qx), center[1]-u*(by+q.Y-qy))
+	c.applyShake(&up)
+	return world, up, int(2 * bx), int(2 * by)
+}
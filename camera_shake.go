@@ -0,0 +1,104 @@
+package dango
+
+import (
+	"math"
+
+	"golang.org/x/image/math/f64"
+)
+
+// CameraShake configures trauma based screen shake, shake strength is
+// trauma squared, so small hits barely move the screen
+type CameraShake struct {
+	MaxOffset float64 // screen pixels at full trauma
+	MaxAngle  float64 // radian at full trauma
+	Decay     float64 // trauma lost per second
+	Frequency float64 // noise samples per second, higher shakes faster
+	Seed      uint64  // same seed gives the same shake, e.g. for replays
+}
+
+// DefaultCameraShake is used until Camera.SetShake is called
+var DefaultCameraShake = CameraShake{MaxOffset: 16, MaxAngle: 0.05, Decay: 1, Frequency: 15}
+
+type shakeState struct {
+	config     CameraShake
+	configured bool
+	trauma     float64
+	time       float64
+	offset     f64.Vec2 // screen offset
+	angle      float64
+}
+
+// SetShake configures the camera shake
+func (c *Camera) SetShake(s CameraShake) {
+	c.shake.config = s
+	c.shake.configured = true
+}
+
+// AddTrauma adds `t` to the shake trauma, trauma is between 0 and 1
+func (c *Camera) AddTrauma(t float64) {
+	c.shake.trauma = Clamp01(c.shake.trauma + t)
+}
+
+// Trauma return current shake trauma between 0 and 1
+func (c *Camera) Trauma() float64 {
+	return c.shake.trauma
+}
+
+// StopShake removes all trauma
+func (c *Camera) StopShake() {
+	c.shake.trauma = 0
+	c.shake.offset = f64.Vec2{}
+	c.shake.angle = 0
+}
+
+// UpdateShake decays trauma and moves the shake offset, call once per frame
+// with `dt` seconds since the last call. The shake is applied to the camera
+// matrix, Position is not changed.
+func (c *Camera) UpdateShake(dt float64) {
+	s := &c.shake
+	cfg := s.config
+	if !s.configured {
+		cfg = DefaultCameraShake
+	}
+	s.time += dt
+	s.trauma = Clamp01(s.trauma - cfg.Decay*dt)
+	if s.trauma == 0 {
+		s.offset = f64.Vec2{}
+		s.angle = 0
+		return
+	}
+
+	amount := s.trauma * s.trauma
+	t := s.time * cfg.Frequency
+	s.offset = f64.Vec2{
+		cfg.MaxOffset * amount * valueNoise(cfg.Seed, 0, t),
+		cfg.MaxOffset * amount * valueNoise(cfg.Seed, 1, t),
+	}
+	s.angle = cfg.MaxAngle * amount * valueNoise(cfg.Seed, 2, t)
+}
+
+// ShakeOffset return current shake offset on screen, and rotation in radian
+func (c *Camera) ShakeOffset() (float64, float64, float64) {
+	return c.shake.offset[0], c.shake.offset[1], c.shake.angle
+}
+
+// valueNoise return smooth noise from -1 to 1 at `t`, for `channel` of `seed`
+func valueNoise(seed, channel uint64, t float64) float64 {
+	i := math.Floor(t)
+	f := t - i
+	f = f * f * (3 - 2*f) // smoothstep
+	a := hashNoise(seed, channel, int64(i))
+	b := hashNoise(seed, channel, int64(i)+1)
+	return Lerp(a, b, f)
+}
+
+// hashNoise return random number from -1 to 1 for seed, channel and i,
+// using splitmix64
+func hashNoise(seed, channel uint64, i int64) float64 {
+	z := seed ^ channel*0x9e3779b97f4a7c15 ^ uint64(i)*0xbf58476d1ce4e5b9
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11)/float64(1<<53)*2 - 1
+}
@@ -1,9 +1,14 @@
 package dango
 
 import (
+	"encoding/json"
+	"errors"
+	"image"
+	"math"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/math/f64"
 )
 
 func TestIsPointInViewport(t *testing.T) {
@@ -57,3 +62,773 @@ func TestMatrixApply(t *testing.T) {
 		t.Errorf("Expect (90,80), got (%f, %f)", wx2, wy2)
 	}
 }
+
+func TestCameraFollowDeadZone(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(100, 100)
+	cam.SetFollow(CameraFollow{DeadZone: [2]float64{20, 20}})
+
+	cam.Follow(5, -8, 1./60)
+	if x, y := cam.GetPosition(); x != 0 || y != 0 {
+		t.Errorf("Expect (0,0) inside dead zone, got (%f, %f)", x, y)
+	}
+	cam.Follow(30, 0, 1./60)
+	if x, y := cam.GetPosition(); x != 20 || y != 0 {
+		t.Errorf("Expect (20,0), got (%f, %f)", x, y)
+	}
+
+	cam.SetFollow(CameraFollow{LockY: true})
+	cam.Follow(50, 50, 1./60)
+	if x, y := cam.GetPosition(); x != 50 || y != 0 {
+		t.Errorf("Expect (50,0) with y locked, got (%f, %f)", x, y)
+	}
+}
+
+func TestCameraFollowFrameRate(t *testing.T) {
+	for _, smoothing := range []FollowSmoothing{FollowExponential, FollowCriticallyDamped} {
+		a := &Camera{}
+		a.SetFollow(CameraFollow{Smoothing: smoothing, SmoothTime: 0.3})
+		b := &Camera{}
+		b.SetFollow(CameraFollow{Smoothing: smoothing, SmoothTime: 0.3})
+		for i := 0; i < 30; i++ {
+			a.Follow(100, 0, 1./30)
+		}
+		for i := 0; i < 120; i++ {
+			b.Follow(100, 0, 1./120)
+		}
+		ax, _ := a.GetPosition()
+		bx, _ := b.GetPosition()
+		if !EqualFloat(ax, bx, 1) || ax <= 50 || ax >= 100 {
+			t.Errorf("Expect same position at 30 and 120 fps, got %f and %f", ax, bx)
+		}
+	}
+}
+
+func TestCameraBounds(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(100, 50)
+	cam.SetBounds(0, 0, 1000, 500)
+
+	cam.SetPosition(-100, 700)
+	if x, y := cam.GetPosition(); x != 50 || y != 475 {
+		t.Errorf("Expect (50,475), got (%f, %f)", x, y)
+	}
+	cam.Pan(2000, 0)
+	if x, _ := cam.GetPosition(); x != 950 {
+		t.Errorf("Expect x 950, got %f", x)
+	}
+
+	cam.Rotate(math.Pi / 2) // view is now 50 wide and 100 tall in the world
+	if x, y := cam.GetPosition(); !EqualFloat(x, 950, 1e-9) || !EqualFloat(y, 450, 1e-9) {
+		t.Errorf("Expect (950,450), got (%f, %f)", x, y)
+	}
+
+	cam.Reset()
+	cam.SetBounds(0, 0, 60, 500)
+	cam.SetPosition(0, 0)
+	if x, _ := cam.GetPosition(); x != 30 {
+		t.Errorf("Expect x centred at 30, got %f", x)
+	}
+	cam.SetBoundsAlign(BoundsMin)
+	if x, _ := cam.GetPosition(); x != 50 {
+		t.Errorf("Expect x pinned at 50, got %f", x)
+	}
+}
+
+func TestCameraResetBounds(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(200, 100)
+	cam.SetBounds(0, 0, 1000, 1000)
+	cam.SetPosition(500, 500)
+	cam.Reset()
+	if x, y := cam.GetPosition(); x != 100 || y != 50 {
+		t.Errorf("Expect reset inside bounds at (100,50), got (%f, %f)", x, y)
+	}
+}
+
+func TestCameraScale(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(100, 100)
+	cam.SetScale(2)
+	if cam.Scale() != 2 {
+		t.Errorf("Expect scale 2, got %f", cam.Scale())
+	}
+	cam.ZoomIn(10)
+	if !EqualFloat(cam.Scale(), 2*math.Pow(1.01, 10), 1e-9) {
+		t.Errorf("Expect ZoomIn on top of scale 2, got %f", cam.Scale())
+	}
+
+	cam.SetZoomLimits(0.5, 4)
+	cam.ZoomBy(10)
+	if cam.Scale() != 4 {
+		t.Errorf("Expect scale limited to 4, got %f", cam.Scale())
+	}
+	cam.ZoomOut(1)
+	if cam.Scale() >= 4 {
+		t.Errorf("Expect ZoomOut below the limit, got %f", cam.Scale())
+	}
+	cam.SetScale(0.1)
+	if cam.Scale() != 0.5 {
+		t.Errorf("Expect scale limited to 0.5, got %f", cam.Scale())
+	}
+
+	cam.SetZoomLimits(0, 0)
+	cam.SetScale(0)
+	cam.SetScale(-2)
+	if cam.Scale() != 0.5 {
+		t.Errorf("Expect scale <= 0 ignored, got %f", cam.Scale())
+	}
+	cam.SetScale(4)
+	cam.ZoomTo(0, 1, nil)
+	for i := 0; i < 10; i++ {
+		cam.UpdateTweens(0.1)
+	}
+	if s := cam.Scale(); !EqualFloat(s, 0.4, 1e-9) {
+		t.Errorf("Expect zoom to 0 to stop at 0.4, got %f", s)
+	}
+}
+
+func TestCameraZoomAt(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(100, 100)
+	cam.SetPosition(50, 50)
+	cam.Rotate(0.3)
+	cam.Update()
+	wx, wy := cam.ScreenToWorld(80, 20)
+
+	cam.ZoomAt(80, 20, 2.5)
+	cam.Update()
+	if cam.Scale() != 2.5 {
+		t.Errorf("Expect scale 2.5, got %f", cam.Scale())
+	}
+	sx, sy := cam.WorldToScreen(wx, wy)
+	if !EqualFloat(sx, 80, 1e-9) || !EqualFloat(sy, 20, 1e-9) {
+		t.Errorf("Expect world point to stay at (80,20), got (%f, %f)", sx, sy)
+	}
+}
+
+func TestCameraShake(t *testing.T) {
+	newCamera := func() *Camera {
+		cam := &Camera{}
+		cam.SetViewPort(100, 100)
+		cam.SetPosition(50, 50)
+		cam.SetShake(CameraShake{MaxOffset: 10, MaxAngle: 0.1, Decay: 0.5, Frequency: 20, Seed: 7})
+		cam.AddTrauma(1)
+		return cam
+	}
+	a, b := newCamera(), newCamera()
+	for i := 0; i < 10; i++ {
+		a.UpdateShake(1. / 60)
+		b.UpdateShake(1. / 60)
+	}
+	ax, ay, aa := a.ShakeOffset()
+	bx, by, ba := b.ShakeOffset()
+	if ax != bx || ay != by || aa != ba {
+		t.Errorf("Expect same shake with same seed, got (%f,%f,%f) and (%f,%f,%f)", ax, ay, aa, bx, by, ba)
+	}
+	if ax == 0 && ay == 0 {
+		t.Errorf("Expect shake offset")
+	}
+	if x, y := a.GetPosition(); x != 50 || y != 50 {
+		t.Errorf("Expect position unchanged, got (%f, %f)", x, y)
+	}
+	a.Update()
+	if sx, sy := a.WorldToScreen(50, 50); EqualFloat(sx, 50, 1e-9) && EqualFloat(sy, 50, 1e-9) {
+		t.Errorf("Expect matrix shaken, got (%f, %f)", sx, sy)
+	}
+
+	for i := 0; i < 120; i++ {
+		a.UpdateShake(1. / 60)
+	}
+	if a.Trauma() != 0 {
+		t.Errorf("Expect trauma decayed to 0, got %f", a.Trauma())
+	}
+	a.Update()
+	if sx, sy := a.WorldToScreen(50, 50); sx != 50 || sy != 50 {
+		t.Errorf("Expect (50,50) after shake, got (%f, %f)", sx, sy)
+	}
+}
+
+func TestCameraVisibleArea(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(100, 50)
+	cam.SetPosition(0, 0)
+	cam.SetScale(2)
+	cam.Update()
+	minX, minY, maxX, maxY := cam.VisibleRect()
+	if !EqualFloat(minX, -25, 1e-9) || !EqualFloat(minY, -12.5, 1e-9) ||
+		!EqualFloat(maxX, 25, 1e-9) || !EqualFloat(maxY, 12.5, 1e-9) {
+		t.Errorf("Expect (-25,-12.5)-(25,12.5), got (%f,%f)-(%f,%f)", minX, minY, maxX, maxY)
+	}
+
+	cam.Rotate(math.Pi / 4)
+	cam.Update()
+	quad := cam.VisibleQuad()
+	for _, p := range quad {
+		if d := math.Hypot(p[0], p[1]); !EqualFloat(d, math.Hypot(25, 12.5), 1e-9) {
+			t.Errorf("Expect corner at distance %f, got %f", math.Hypot(25, 12.5), d)
+		}
+	}
+
+	// inside the bounding box of the rotated view, but not on screen
+	if cam.IsRectInViewport(20, 20, 2, 2) {
+		t.Errorf("Expect rect at corner of bounding box off screen")
+	}
+	if !cam.IsRectInViewport(-100, -1, 200, 2) {
+		t.Errorf("Expect rect crossing the view on screen")
+	}
+	if cam.IsCircleInViewport(0, 100, 10) {
+		t.Errorf("Expect circle off screen")
+	}
+	if !cam.IsCircleInViewport(0, 20, 10) {
+		t.Errorf("Expect circle on screen")
+	}
+}
+
+func TestCameraTween(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(100, 100)
+	cam.SetRotation(3)
+
+	completed := 0
+	pan := cam.PanTo(100, 50, 1, EaseInOutQuad)
+	pan.OnComplete = func() { completed++ }
+	rot := cam.RotateTo(-3, 1, nil)
+	zoom := pan.Then(NewWaitTween(0.5)).Then(NewZoomTween(4, 1, nil))
+
+	for i := 0; i < 10; i++ {
+		cam.UpdateTweens(0.1)
+	}
+	if x, y := cam.GetPosition(); !EqualFloat(x, 100, 1e-9) || !EqualFloat(y, 50, 1e-9) {
+		t.Errorf("Expect (100,50), got (%f, %f)", x, y)
+	}
+	if !pan.Done() || !rot.Done() || completed != 1 {
+		t.Errorf("Expect pan and rotate done, got %t and %t", pan.Done(), rot.Done())
+	}
+	if !EqualFloat(cam.Rotation, 2*math.Pi-3, 1e-9) {
+		t.Errorf("Expect rotation the short way to %f, got %f", 2*math.Pi-3, cam.Rotation)
+	}
+	if cam.Scale() != 1 {
+		t.Errorf("Expect zoom waiting, got scale %f", cam.Scale())
+	}
+
+	for i := 0; i < 10; i++ {
+		cam.UpdateTweens(0.1)
+	}
+	if !EqualFloat(cam.Scale(), 2, 1e-9) {
+		t.Errorf("Expect scale 2 half way in log space, got %f", cam.Scale())
+	}
+	zoom.Cancel()
+	cam.UpdateTweens(0.1)
+	if !EqualFloat(cam.Scale(), 2, 1e-9) || cam.IsAnimating() {
+		t.Errorf("Expect cancelled zoom to stop at 2, got %f", cam.Scale())
+	}
+}
+
+func TestCameraViewPortRect(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPortRect(400, 0, 400, 300) // right half of 800x300 screen
+	cam.SetPosition(10, 20)
+	cam.Update()
+	if sx, sy := cam.WorldToScreen(10, 20); sx != 600 || sy != 150 {
+		t.Errorf("Expect (600,150), got (%f, %f)", sx, sy)
+	}
+	if wx, wy := cam.ScreenToWorld(600, 150); wx != 10 || wy != 20 {
+		t.Errorf("Expect (10,20), got (%f, %f)", wx, wy)
+	}
+	if cam.IsPointInViewport(-300, 20) {
+		t.Errorf("Expect point on left half outside viewport")
+	}
+	if !cam.IsPointInViewport(100, 20) {
+		t.Errorf("Expect point inside viewport")
+	}
+	if r := cam.ViewPortRect(); r != image.Rect(400, 0, 800, 300) {
+		t.Errorf("Expect (400,0)-(800,300), got %v", r)
+	}
+}
+
+func TestParallaxLayer(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(200, 100)
+	cam.SetPosition(100, 50)
+	cam.SetScale(2)
+	cam.Update()
+
+	world := &ParallaxLayer{Scroll: f64.Vec2{1, 1}, Zoom: 1}
+	m := world.GeoM(cam)
+	if x, y := m.Apply(30, 40); x != -40 || y != 30 {
+		t.Errorf("Expect (-40,30), got (%f, %f)", x, y)
+	}
+
+	// fixed to the viewport, origin at the top left
+	sky := &ParallaxLayer{}
+	for _, pos := range []f64.Vec2{{100, 50}, {-700, 1200}} {
+		cam.SetPosition(pos[0], pos[1])
+		m = sky.GeoM(cam)
+		if x, y := m.Apply(0, 0); !EqualFloat(x, 0, 1e-9) || !EqualFloat(y, 0, 1e-9) {
+			t.Errorf("Expect sky origin at (0,0), got (%f, %f)", x, y)
+		}
+	}
+	cam.SetViewPortRect(40, 30, 200, 100)
+	m = sky.GeoM(cam)
+	if x, y := m.Apply(0, 0); !EqualFloat(x, 40, 1e-9) || !EqualFloat(y, 30, 1e-9) {
+		t.Errorf("Expect sky origin at viewport (40,30), got (%f, %f)", x, y)
+	}
+	cam.SetViewPortRect(0, 0, 200, 100)
+
+	// half speed, not zoomed
+	cam.SetPosition(300, 150)
+	far := &ParallaxLayer{Scroll: f64.Vec2{0.5, 0.5}}
+	m = far.GeoM(cam)
+	if x, y := m.Apply(0, 0); !EqualFloat(x, -100, 1e-9) || !EqualFloat(y, -50, 1e-9) {
+		t.Errorf("Expect (-100,-50), got (%f, %f)", x, y)
+	}
+
+	far.Image = ebiten.NewImage(64, 32)
+	far.RepeatX = true
+	// layer visible from x 100 to 300
+	if x0, y0, x1, y1 := far.tiles(cam, m); x0 != 1 || x1 != 4 || y0 != 0 || y1 != 0 {
+		t.Errorf("Expect tiles (1,0)-(4,0), got (%d,%d)-(%d,%d)", x0, y0, x1, y1)
+	}
+}
+
+func TestCameraPixelPerfect(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(320, 180)
+	cam.SetScale(2.8)
+	cam.SetPosition(10.3, 20.6)
+	cam.SetRotation(0.3)
+	cam.SetPixelPerfect(true)
+	cam.Update()
+	if cam.PixelScale() != 3 {
+		t.Errorf("Expect pixel scale 3, got %d", cam.PixelScale())
+	}
+
+	world, up, w, h := cam.pixelMatrices()
+	if w != 2*(54+1) || h != 2*(30+1) {
+		t.Errorf("Expect buffer 110x62, got %dx%d", w, h)
+	}
+	// drawing through the buffer lands where WorldToScreen says
+	m := world
+	m.Concat(up)
+	for _, p := range [][2]float64{{0, 0}, {10.3, 20.6}, {-40, 75}} {
+		x, y := m.Apply(p[0], p[1])
+		sx, sy := cam.WorldToScreen(p[0], p[1])
+		if !EqualFloat(x, sx, 1e-9) || !EqualFloat(y, sy, 1e-9) {
+			t.Errorf("Expect (%f,%f), got (%f,%f)", sx, sy, x, y)
+		}
+	}
+
+	// the world is drawn on whole texels, sub-texel shift only at upscale
+	cam.SetRotation(0)
+	cam.Update()
+	world, up, _, _ = cam.pixelMatrices()
+	x, y := world.Apply(0, 0)
+	if x != math.Floor(x) || y != math.Floor(y) {
+		t.Errorf("Expect whole texels, got (%f,%f)", x, y)
+	}
+	if sx, sy := cam.WorldToScreen(10.3, 20.6); sx != 160 || sy != 90 {
+		t.Errorf("Expect position at viewport centre, got (%f,%f)", sx, sy)
+	}
+}
+
+// fakeInput is an input.Source with fixed state
+type fakeInput struct {
+	cursor  [2]int
+	wheel   float64
+	buttons map[ebiten.MouseButton]bool
+	keys    map[ebiten.Key]bool
+	touches map[ebiten.TouchID][2]int
+}
+
+func (f *fakeInput) CursorPosition() (int, int)                     { return f.cursor[0], f.cursor[1] }
+func (f *fakeInput) Wheel() (float64, float64)                      { return 0, f.wheel }
+func (f *fakeInput) IsMouseButtonPressed(b ebiten.MouseButton) bool { return f.buttons[b] }
+func (f *fakeInput) IsMouseButtonJustPressed(ebiten.MouseButton) bool {
+	return false
+}
+func (f *fakeInput) IsMouseButtonJustReleased(ebiten.MouseButton) bool {
+	return false
+}
+func (f *fakeInput) IsKeyPressed(k ebiten.Key) bool    { return f.keys[k] }
+func (f *fakeInput) IsKeyJustPressed(ebiten.Key) bool  { return false }
+func (f *fakeInput) IsKeyJustReleased(ebiten.Key) bool { return false }
+func (f *fakeInput) AppendTouchIDs(ids []ebiten.TouchID) []ebiten.TouchID {
+	for id := ebiten.TouchID(0); id < 10; id++ {
+		if _, ok := f.touches[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+func (f *fakeInput) TouchPosition(id ebiten.TouchID) (int, int) {
+	return f.touches[id][0], f.touches[id][1]
+}
+
+func TestCameraController(t *testing.T) {
+	in := &fakeInput{
+		buttons: map[ebiten.MouseButton]bool{},
+		keys:    map[ebiten.Key]bool{},
+		touches: map[ebiten.TouchID][2]int{},
+	}
+	cam := &Camera{}
+	cam.SetViewPort(200, 100)
+	cam.SetRotation(math.Pi / 2)
+	cam.SetScale(2)
+	cc := NewCameraController(cam)
+	cc.Source = in
+	near := func(x, y, ex, ey float64) bool {
+		return EqualFloat(x, ex, 1e-9) && EqualFloat(y, ey, 1e-9)
+	}
+
+	// drag, world point stays under the cursor
+	in.cursor = [2]int{100, 50}
+	in.buttons[ebiten.MouseButtonRight] = true
+	cc.Update(0.1)
+	in.cursor = [2]int{110, 50}
+	cc.Update(0.1)
+	cam.Update()
+	if x, y := cam.WorldToScreen(0, 0); !near(x, y, 110, 50) {
+		t.Errorf("Expect (110,50), got (%f, %f)", x, y)
+	}
+
+	// fling after release, slowing down
+	in.buttons[ebiten.MouseButtonRight] = false
+	cc.Update(0.1)
+	cam.Update()
+	x1, _ := cam.WorldToScreen(0, 0)
+	cc.Update(0.1)
+	cam.Update()
+	x2, _ := cam.WorldToScreen(0, 0)
+	if !EqualFloat(x1, 120, 1e-9) || x2-x1 <= 0 || x2-x1 >= 10 {
+		t.Errorf("Expect fling to 120 and slowing, got %f, %f", x1, x2)
+	}
+	cc.Stop()
+
+	// pinch, zoom and rotate around the midpoint
+	cam.Update()
+	wx, wy := cam.ScreenToWorld(100, 50)
+	in.touches[0] = [2]int{50, 50}
+	in.touches[1] = [2]int{150, 50}
+	cc.Update(0.1)
+	in.touches[0] = [2]int{100, -25}
+	in.touches[1] = [2]int{100, 125}
+	cc.Update(0.1)
+	cam.Update()
+	if !EqualFloat(cam.Scale(), 3, 1e-9) || !EqualFloat(cam.Rotation, math.Pi, 1e-9) {
+		t.Errorf("Expect scale 3 and rotation pi, got %f, %f", cam.Scale(), cam.Rotation)
+	}
+	if x, y := cam.WorldToScreen(wx, wy); !near(x, y, 100, 50) {
+		t.Errorf("Expect pinch point at (100,50), got (%f, %f)", x, y)
+	}
+	delete(in.touches, 0)
+	delete(in.touches, 1)
+	cc.Stop()
+
+	// keys move the view
+	cam.Update()
+	x0, y0 := cam.WorldToScreen(0, 0)
+	in.keys[ebiten.KeyD] = true
+	cc.Update(0.5)
+	cam.Update()
+	if x, y := cam.WorldToScreen(0, 0); !near(x, y, x0-300, y0) {
+		t.Errorf("Expect (%f,%f), got (%f, %f)", x0-300, y0, x, y)
+	}
+}
+
+func TestCameraMatrixInvalidation(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPort(100, 100)
+	cam.Update()
+	if x, y := cam.WorldToScreen(0, 0); x != 50 || y != 50 {
+		t.Errorf("Expect (50,50), got (%f, %f)", x, y)
+	}
+
+	// public fields changed without Update
+	cam.Position = f64.Vec2{10, 20}
+	cam.ZoomFactor = 70
+	if x, y := cam.WorldToScreen(10, 20); x != 50 || y != 50 {
+		t.Errorf("Expect (50,50), got (%f, %f)", x, y)
+	}
+	if x, y := cam.ScreenToWorld(50, 50); !EqualFloat(x, 10, 1e-9) || !EqualFloat(y, 20, 1e-9) {
+		t.Errorf("Expect (10,20), got (%f, %f)", x, y)
+	}
+	m := cam.GeoM()
+	if x, y := m.Apply(10, 20); x != 50 || y != 50 {
+		t.Errorf("Expect GeoM to follow Position, got (%f, %f)", x, y)
+	}
+
+	cam.Rotation = math.Pi
+	cam.ViewPort = f64.Vec2{200, 100}
+	if x, y := cam.WorldToScreen(10, 20); !EqualFloat(x, 100, 1e-9) || !EqualFloat(y, 50, 1e-9) {
+		t.Errorf("Expect (100,50), got (%f, %f)", x, y)
+	}
+
+	// unchanged state reuses the matrix and its inverse
+	mc := cam.matrices()
+	mc.inverse = ebiten.GeoM{}
+	if x, y := cam.ScreenToWorld(3, 4); x != 3 || y != 4 {
+		t.Errorf("Expect cached inverse to be used, got (%f, %f)", x, y)
+	}
+}
+
+func TestCameraEncoding(t *testing.T) {
+	cam := &Camera{}
+	cam.SetViewPortRect(10, 20, 320, 240)
+	cam.SetPosition(12.5, -7)
+	cam.SetScale(1.5)
+	cam.ZoomIn(3)
+	cam.SetRotation(0.25)
+
+	data, err := json.Marshal(cam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &Camera{}
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.String() != cam.String() || restored.ViewPortRect() != cam.ViewPortRect() {
+		t.Errorf("Expect %v, got %v", cam, restored)
+	}
+
+	data, err = cam.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored = &Camera{}
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Matrix() != cam.Matrix() {
+		t.Errorf("Expect %v, got %v", cam.Matrix(), restored.Matrix())
+	}
+
+	// restored view stays in the bounds of the receiving camera
+	bounded := &Camera{}
+	bounded.SetBounds(0, 0, 1000, 1000)
+	if err := bounded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if x, _ := bounded.GetPosition(); x < 100 {
+		t.Errorf("Expect position inside bounds, got %f", x)
+	}
+
+	// data from a newer version is rejected
+	data[0] = cameraVersion + 1
+	if err := restored.UnmarshalBinary(data); !errors.Is(err, ErrCameraVersion) {
+		t.Errorf("Expect ErrCameraVersion, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"version":99}`), restored); !errors.Is(err, ErrCameraVersion) {
+		t.Errorf("Expect ErrCameraVersion, got %v", err)
+	}
+
+	// fields added later are optional
+	short := marshalCameraFields([]float64{1, 2})
+	if err := restored.UnmarshalBinary(short); err != nil || restored.Position != (f64.Vec2{1, 2}) || restored.Scale() != 1 {
+		t.Errorf("Expect position (1,2) and scale 1, got %v, %v", restored, err)
+	}
+}
+
+func TestCameraEncodingByValue(t *testing.T) {
+	type document struct {
+		Name   string
+		View   Camera
+		View3D Camera3D
+	}
+	doc := document{Name: "level"}
+	doc.View.SetViewPort(320, 240)
+	doc.View.SetPosition(12, 34)
+	doc.View.SetScale(2)
+	doc.View3D = *NewCamera3D(Vector3{0, 10, -50}, Vector3{}, 90, 640, 480)
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored document
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Expect round trip of cameras held by value, got %v", err)
+	}
+	if restored.View.String() != doc.View.String() || restored.View.ViewPort != doc.View.ViewPort {
+		t.Errorf("Expect %v, got %v", &doc.View, &restored.View)
+	}
+	p := Vector3{5, 3, 20}
+	if a, b := doc.View3D.PosToScreen(p), restored.View3D.PosToScreen(p); a[0] != b[0] || a[1] != b[1] {
+		t.Errorf("Expect %v, got %v", a, b)
+	}
+}
+
+func TestCamera3DEncoding(t *testing.T) {
+	cam := NewCamera3D(Vector3{0, 10, -50}, Vector3{0, 0, 0}, 90, 640, 480)
+	cam.SetNearFar(1, 200)
+	cam.SetProjection(ProjectionOrthographic)
+	cam.SetViewHeight(30)
+	cam.Update()
+	p := Vector3{5, 3, 20}
+	for _, enc := range []string{"json", "binary"} {
+		var data []byte
+		var err error
+		restored := &Camera3D{}
+		if enc == "json" {
+			data, err = json.Marshal(cam)
+			if err == nil {
+				err = json.Unmarshal(data, restored)
+			}
+		} else {
+			data, err = cam.MarshalBinary()
+			if err == nil {
+				err = restored.UnmarshalBinary(data)
+			}
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", enc, err)
+		}
+		a, b := cam.PosToScreen(p), restored.PosToScreen(p)
+		for i := range a {
+			if a[i] != b[i] {
+				t.Errorf("%s: Expect %v, got %v", enc, a, b)
+				break
+			}
+		}
+	}
+}
+
+func TestMinimap(t *testing.T) {
+	// 2000x1000 world on a 200x100 minimap at the top right of 800x600 screen
+	mm := NewMinimap(600, 0, 200, 100, 0, 0, 2000, 1000)
+	if !EqualFloat(mm.Camera.Scale(), 0.1, 1e-9) {
+		t.Errorf("Expect scale 0.1, got %f", mm.Camera.Scale())
+	}
+	if x, y := mm.Camera.WorldToScreen(0, 0); !EqualFloat(x, 600, 1e-9) || !EqualFloat(y, 0, 1e-9) {
+		t.Errorf("Expect world origin at (600,0), got (%f, %f)", x, y)
+	}
+
+	main := &Camera{}
+	main.SetViewPort(800, 600)
+	if !mm.JumpTo(main, 700, 25) {
+		t.Errorf("Expect click inside minimap")
+	}
+	if x, y := main.GetPosition(); !EqualFloat(x, 1000, 1e-9) || !EqualFloat(y, 250, 1e-9) {
+		t.Errorf("Expect (1000,250), got (%f, %f)", x, y)
+	}
+	if mm.JumpTo(main, 100, 25) {
+		t.Errorf("Expect click outside minimap")
+	}
+
+	mm.AddMarker(10, 10, 2, nil)
+	screen := ebiten.NewImage(800, 600)
+	mm.Draw(screen, main, nil)
+	mm.ClearMarkers()
+	if len(mm.Markers) != 0 {
+		t.Errorf("Expect no markers, got %d", len(mm.Markers))
+	}
+}
+
+func TestCamera3DProjection(t *testing.T) {
+	cam := NewCamera3D(Vector3{0, 0, -100}, Vector3{0, 0, 0}, 90, 200, 200)
+	x := func(p Vector3) float64 {
+		return float64(cam.PosToScreen(p)[0])
+	}
+	if !EqualFloat(x(Vector3{10, 0, 0}), 110, 1e-4) || !EqualFloat(x(Vector3{10, 0, 100}), 105, 1e-4) {
+		t.Errorf("Expect perspective 110 and 105, got %f and %f", x(Vector3{10, 0, 0}), x(Vector3{10, 0, 100}))
+	}
+
+	// default view height matches perspective at the look at point
+	cam.SetProjection(ProjectionOrthographic)
+	cam.Update()
+	if !EqualFloat(x(Vector3{10, 0, 0}), 110, 1e-4) || !EqualFloat(x(Vector3{10, 0, 100}), 110, 1e-4) {
+		t.Errorf("Expect orthographic 110, got %f and %f", x(Vector3{10, 0, 0}), x(Vector3{10, 0, 100}))
+	}
+	cam.SetViewHeight(40)
+	cam.Update()
+	if s := cam.WorldToScreen([]float64{10, 0, 50}); !EqualFloat(s[0], 150, 1e-9) || s[3] != 1 {
+		t.Errorf("Expect x 150 and w 1, got %v", s)
+	}
+
+	// near and far planes, screen depth from 0.5 at near to 1 at far
+	cam.SetNearFar(10, 110)
+	cam.Update()
+	if z := cam.PosToScreen(Vector3{0, 0, -50})[2]; !EqualFloat(float64(z), 0.7, 1e-6) {
+		t.Errorf("Expect depth 0.7, got %f", z)
+	}
+	line, ok := cam.LineToScreen(Vector3{10, 0, -200}, Vector3{10, 0, 0})
+	if !ok || !EqualFloat(float64(line[0]), 150, 1e-4) || !EqualFloat(float64(line[2]), 0.5, 1e-6) {
+		t.Errorf("Expect line clipped at near plane, got %v", line)
+	}
+
+	// back to perspective with the same camera
+	cam.SetProjection(ProjectionPerspective)
+	cam.Update()
+	if z := cam.PosToScreen(Vector3{0, 0, -90})[2]; !EqualFloat(float64(z), 0.5, 1e-6) {
+		t.Errorf("Expect depth 0.5 at near plane, got %f", z)
+	}
+	if !EqualFloat(x(Vector3{10, 0, 0}), 110, 1e-4) {
+		t.Errorf("Expect perspective 110, got %f", x(Vector3{10, 0, 0}))
+	}
+}
+
+func TestCamera3DFrustum(t *testing.T) {
+	// visible where |x| and |y| <= z + 100, from z -95 to 400
+	cam := NewCamera3D(Vector3{0, 0, -100}, Vector3{0, 0, 0}, 90, 200, 200)
+	near := cam.FrustumPlanes()[FrustumNear]
+	if !EqualFloat(near.Normal.Z, 1, 1e-9) || !EqualFloat(near.Distance(Vector3{}), 95, 1e-9) {
+		t.Errorf("Expect near plane normal (0,0,1) 95 from origin, got %v", near)
+	}
+
+	points := []struct {
+		p       Vector3
+		visible bool
+	}{
+		{Vector3{0, 0, 0}, true},
+		{Vector3{90, -90, 0}, true},
+		{Vector3{150, 0, 0}, false},
+		{Vector3{0, 150, 0}, false},
+		{Vector3{0, 0, -97}, false},
+		{Vector3{0, 0, 450}, false},
+	}
+	for _, c := range points {
+		if cam.PointInFrustum(c.p) != c.visible {
+			t.Errorf("Expect point %v visible %v", c.p, c.visible)
+		}
+	}
+
+	spheres := []struct {
+		c    Vector3
+		r    float64
+		want Visibility
+	}{
+		{Vector3{0, 0, 0}, 10, VisibilityInside},
+		{Vector3{100, 0, 0}, 10, VisibilityIntersecting},
+		{Vector3{130, 0, 0}, 10, VisibilityOutside},
+		{Vector3{0, 0, -110}, 10, VisibilityOutside},
+	}
+	for _, c := range spheres {
+		if got := cam.SphereInFrustum(c.c, c.r); got != c.want {
+			t.Errorf("Expect sphere %v %v, got %v", c.c, c.want, got)
+		}
+	}
+
+	boxes := []struct {
+		min, max Vector3
+		want     Visibility
+	}{
+		{Vector3{-10, -10, -10}, Vector3{10, 10, 10}, VisibilityInside},
+		{Vector3{90, -1, -1}, Vector3{110, 1, 1}, VisibilityIntersecting},
+		{Vector3{-10, -10, 390}, Vector3{10, 10, 410}, VisibilityIntersecting},
+		{Vector3{200, -1, -1}, Vector3{210, 1, 1}, VisibilityOutside},
+	}
+	for _, c := range boxes {
+		if got := cam.BoxInFrustum(c.min, c.max); got != c.want {
+			t.Errorf("Expect box %v-%v %v, got %v", c.min, c.max, c.want, got)
+		}
+	}
+
+	// orthographic, |x| <= 20 at any depth
+	cam.SetProjection(ProjectionOrthographic)
+	cam.SetViewHeight(40)
+	cam.Update()
+	if !cam.PointInFrustum(Vector3{15, 0, 300}) || cam.PointInFrustum(Vector3{25, 0, 300}) {
+		t.Errorf("Expect orthographic frustum from x -20 to 20")
+	}
+}
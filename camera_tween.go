@@ -0,0 +1,217 @@
+package dango
+
+import (
+	"math"
+
+	"golang.org/x/image/math/f64"
+)
+
+type tweenKind int
+
+const (
+	tweenPan tweenKind = iota
+	tweenZoom
+	tweenRotate
+	tweenWait
+)
+
+// CameraTween animates camera position, scale or rotation over time,
+// start it with Camera.Play, chain with Then
+type CameraTween struct {
+	kind     tweenKind
+	to       f64.Vec2 // target position, or scale/rotation in to[0]
+	from     f64.Vec2 // captured when the tween starts
+	duration float64  // seconds
+	elapsed  float64
+	ease     Easing
+
+	camera    *Camera
+	done      bool
+	cancelled bool
+	next      []*CameraTween
+
+	// OnComplete is called when the tween finishes, not when cancelled
+	OnComplete func()
+}
+
+// NewPanTween moves the camera to world position x, y
+func NewPanTween(x, y, duration float64, ease Easing) *CameraTween {
+	return &CameraTween{kind: tweenPan, to: f64.Vec2{x, y}, duration: duration, ease: ease}
+}
+
+// NewZoomTween zooms the camera to `scale`, see Camera.SetScale, `scale`
+// <= 0 stops at the last step above 0
+func NewZoomTween(scale, duration float64, ease Easing) *CameraTween {
+	return &CameraTween{kind: tweenZoom, to: f64.Vec2{scale}, duration: duration, ease: ease}
+}
+
+// NewRotateTween rotates the camera to `angle` radian, the short way round
+func NewRotateTween(angle, duration float64, ease Easing) *CameraTween {
+	return &CameraTween{kind: tweenRotate, to: f64.Vec2{angle}, duration: duration, ease: ease}
+}
+
+// NewWaitTween does nothing for `duration`, e.g. pause in a chain
+func NewWaitTween(duration float64) *CameraTween {
+	return &CameraTween{kind: tweenWait, duration: duration}
+}
+
+// Then starts `next` when this tween completes, and return `next`,
+// call Then more than once to start tweens in parallel
+func (t *CameraTween) Then(next *CameraTween) *CameraTween {
+	t.next = append(t.next, next)
+	return next
+}
+
+// Done return true when the tween has completed
+func (t *CameraTween) Done() bool {
+	return t.done
+}
+
+// Cancel stops the tween and the tweens chained after it, the camera
+// stays where it is
+func (t *CameraTween) Cancel() {
+	t.cancelled = true
+	for _, n := range t.next {
+		n.Cancel()
+	}
+}
+
+// Cancelled return true if the tween was cancelled
+func (t *CameraTween) Cancelled() bool {
+	return t.cancelled
+}
+
+// start captures the current camera value
+func (t *CameraTween) start(c *Camera) {
+	t.camera = c
+	t.elapsed = 0
+	switch t.kind {
+	case tweenPan:
+		t.from = c.Position
+	case tweenZoom:
+		t.from = f64.Vec2{c.Scale()}
+	case tweenRotate:
+		t.from = f64.Vec2{c.Rotation}
+	}
+}
+
+// advance by `dt` seconds, return time left over after completing
+func (t *CameraTween) advance(dt float64) float64 {
+	t.elapsed += dt
+	progress := 1.
+	if t.duration > 0 {
+		progress = t.elapsed / t.duration
+	}
+	if progress > 1-1e-9 {
+		// accumulated frame times are not exact, e.g. 10 * 0.1
+		progress = 1
+	}
+	// easing curves may overshoot, e.g. EaseOutBack, only the end is exact
+	p := 1.
+	if progress < 1 {
+		p = progress
+		if t.ease != nil {
+			p = t.ease(progress)
+		}
+	}
+
+	c := t.camera
+	switch t.kind {
+	case tweenPan:
+		c.SetPosition(Lerp(t.from[0], t.to[0], p), Lerp(t.from[1], t.to[1], p))
+	case tweenZoom:
+		// interpolate in log space, so zoom speed looks constant
+		if t.from[0] > 0 && t.to[0] > 0 {
+			c.SetScale(t.from[0] * math.Pow(t.to[0]/t.from[0], p))
+		} else {
+			c.SetScale(Lerp(t.from[0], t.to[0], p))
+		}
+	case tweenRotate:
+		delta := math.Remainder(t.to[0]-t.from[0], 2*math.Pi)
+		c.SetRotation(t.from[0] + delta*p)
+	}
+
+	if progress < 1 {
+		return 0
+	}
+	t.done = true
+	return math.Max(t.elapsed-t.duration, 0)
+}
+
+// Play starts `tweens` in parallel, with tweens already playing
+func (c *Camera) Play(tweens ...*CameraTween) {
+	for _, t := range tweens {
+		t.start(c)
+		c.tweens = append(c.tweens, t)
+	}
+}
+
+// PanTo moves the camera to world position x, y over `duration` seconds
+func (c *Camera) PanTo(x, y, duration float64, ease Easing) *CameraTween {
+	t := NewPanTween(x, y, duration, ease)
+	c.Play(t)
+	return t
+}
+
+// ZoomTo zooms the camera to `scale` over `duration` seconds
+func (c *Camera) ZoomTo(scale, duration float64, ease Easing) *CameraTween {
+	t := NewZoomTween(scale, duration, ease)
+	c.Play(t)
+	return t
+}
+
+// RotateTo rotates the camera to `angle` radian over `duration` seconds
+func (c *Camera) RotateTo(angle, duration float64, ease Easing) *CameraTween {
+	t := NewRotateTween(angle, duration, ease)
+	c.Play(t)
+	return t
+}
+
+// UpdateTweens advances playing tweens by `dt` seconds, call once per frame,
+// e.g. with 1/ebiten.TPS() for fixed timestep
+func (c *Camera) UpdateTweens(dt float64) {
+	type step struct {
+		tween *CameraTween
+		dt    float64
+	}
+	var steps []step
+	for _, t := range c.tweens {
+		steps = append(steps, step{t, dt})
+	}
+	c.tweens = nil
+	for len(steps) > 0 {
+		s := steps[0]
+		steps = steps[1:]
+		if s.tween.cancelled {
+			continue
+		}
+		left := s.tween.advance(s.dt)
+		if !s.tween.done {
+			c.tweens = append(c.tweens, s.tween)
+			continue
+		}
+		if s.tween.OnComplete != nil {
+			s.tween.OnComplete()
+		}
+		// chained tweens start with the time left over this frame
+		for _, n := range s.tween.next {
+			if !n.cancelled {
+				n.start(c)
+				steps = append(steps, step{n, left})
+			}
+		}
+	}
+}
+
+// StopTweens cancels all playing tweens
+func (c *Camera) StopTweens() {
+	for _, t := range c.tweens {
+		t.Cancel()
+	}
+	c.tweens = nil
+}
+
+// IsAnimating return true while any tween is playing
+func (c *Camera) IsAnimating() bool {
+	return len(c.tweens) > 0
+}
@@ -0,0 +1,54 @@
+package dango
+
+import "math"
+
+// Easing maps linear progress t, 0 to 1, to eased progress
+type Easing func(t float64) float64
+
+// Common easing curves, see https://easings.net
+var (
+	EaseLinear Easing = func(t float64) float64 {
+		return t
+	}
+	EaseInQuad Easing = func(t float64) float64 {
+		return t * t
+	}
+	EaseOutQuad Easing = func(t float64) float64 {
+		return 1 - (1-t)*(1-t)
+	}
+	EaseInOutQuad Easing = func(t float64) float64 {
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return 1 - math.Pow(-2*t+2, 2)/2
+	}
+	EaseInCubic Easing = func(t float64) float64 {
+		return t * t * t
+	}
+	EaseOutCubic Easing = func(t float64) float64 {
+		return 1 - math.Pow(1-t, 3)
+	}
+	EaseInOutCubic Easing = func(t float64) float64 {
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		return 1 - math.Pow(-2*t+2, 3)/2
+	}
+	EaseInOutSine Easing = func(t float64) float64 {
+		return -(math.Cos(math.Pi*t) - 1) / 2
+	}
+	EaseOutBack Easing = func(t float64) float64 {
+		c1 := 1.70158
+		c3 := c1 + 1
+		return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+	}
+)
+
+// ease applies `e` on t clamped to 0 - 1, nil is linear
+func ease(e Easing, t float64) float64 {
+	t = Clamp01(t)
+	if e == nil {
+		return t
+	}
+	return e(t)
+}
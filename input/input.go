@@ -0,0 +1,121 @@
+// Package input reads mouse, keyboard, wheel and touch input through a Source,
+// ebiten by default. Replace the Source to drive scenes and ui with
+// scripted input, e.g. in tests.
+package input
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Source of input
+type Source interface {
+	CursorPosition() (int, int)
+	Wheel() (float64, float64)
+	IsMouseButtonPressed(b ebiten.MouseButton) bool
+	IsMouseButtonJustPressed(b ebiten.MouseButton) bool
+	IsMouseButtonJustReleased(b ebiten.MouseButton) bool
+	IsKeyPressed(k ebiten.Key) bool
+	IsKeyJustPressed(k ebiten.Key) bool
+	IsKeyJustReleased(k ebiten.Key) bool
+	AppendTouchIDs(touches []ebiten.TouchID) []ebiten.TouchID
+	TouchPosition(id ebiten.TouchID) (int, int)
+}
+
+// Ebiten reads input from ebiten and inpututil
+type Ebiten struct{}
+
+func (Ebiten) CursorPosition() (int, int) {
+	return ebiten.CursorPosition()
+}
+
+func (Ebiten) Wheel() (float64, float64) {
+	return ebiten.Wheel()
+}
+
+func (Ebiten) IsMouseButtonPressed(b ebiten.MouseButton) bool {
+	return ebiten.IsMouseButtonPressed(b)
+}
+
+func (Ebiten) IsMouseButtonJustPressed(b ebiten.MouseButton) bool {
+	return inpututil.IsMouseButtonJustPressed(b)
+}
+
+func (Ebiten) IsMouseButtonJustReleased(b ebiten.MouseButton) bool {
+	return inpututil.IsMouseButtonJustReleased(b)
+}
+
+func (Ebiten) IsKeyPressed(k ebiten.Key) bool {
+	return ebiten.IsKeyPressed(k)
+}
+
+func (Ebiten) IsKeyJustPressed(k ebiten.Key) bool {
+	return inpututil.IsKeyJustPressed(k)
+}
+
+func (Ebiten) IsKeyJustReleased(k ebiten.Key) bool {
+	return inpututil.IsKeyJustReleased(k)
+}
+
+func (Ebiten) AppendTouchIDs(touches []ebiten.TouchID) []ebiten.TouchID {
+	return ebiten.AppendTouchIDs(touches)
+}
+
+func (Ebiten) TouchPosition(id ebiten.TouchID) (int, int) {
+	return ebiten.TouchPosition(id)
+}
+
+var source Source = Ebiten{}
+
+// SetSource replaces the input source, nil restores ebiten input
+func SetSource(s Source) {
+	if s == nil {
+		s = Ebiten{}
+	}
+	source = s
+}
+
+// CurrentSource return the input source in use
+func CurrentSource() Source {
+	return source
+}
+
+func CursorPosition() (int, int) {
+	return source.CursorPosition()
+}
+
+func Wheel() (float64, float64) {
+	return source.Wheel()
+}
+
+func IsMouseButtonPressed(b ebiten.MouseButton) bool {
+	return source.IsMouseButtonPressed(b)
+}
+
+func IsMouseButtonJustPressed(b ebiten.MouseButton) bool {
+	return source.IsMouseButtonJustPressed(b)
+}
+
+func IsMouseButtonJustReleased(b ebiten.MouseButton) bool {
+	return source.IsMouseButtonJustReleased(b)
+}
+
+func IsKeyPressed(k ebiten.Key) bool {
+	return source.IsKeyPressed(k)
+}
+
+func IsKeyJustPressed(k ebiten.Key) bool {
+	return source.IsKeyJustPressed(k)
+}
+
+func IsKeyJustReleased(k ebiten.Key) bool {
+	return source.IsKeyJustReleased(k)
+}
+
+func AppendTouchIDs(touches []ebiten.TouchID) []ebiten.TouchID {
+	return source.AppendTouchIDs(touches)
+}
+
+func TouchPosition(id ebiten.TouchID) (int, int) {
+	return source.TouchPosition(id)
+}
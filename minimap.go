@@ -0,0 +1,120 @@
+package dango
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/math/f64"
+)
+
+// MinimapMarker is a dot on the minimap at a world position
+type MinimapMarker struct {
+	Position f64.Vec2
+	Radius   float32 // screen pixels
+	Color    color.Color
+}
+
+// Minimap draws an overview of the world through its own Camera, the
+// camera's viewport is where the minimap is on screen
+type Minimap struct {
+	Camera     *Camera
+	Background color.Color // nil for transparent
+	FrameColor color.Color // visible area of the main camera, nil for none
+	FrameWidth float32
+	Markers    []MinimapMarker
+}
+
+// NewMinimap create a minimap at screen rectangle x, y, w, h showing world
+// rectangle minX, minY to maxX, maxY
+func NewMinimap(x, y, w, h int, minX, minY, maxX, maxY float64) *Minimap {
+	m := &Minimap{
+		Camera:     &Camera{},
+		Background: color.RGBA{0, 0, 0, 0xc0},
+		FrameColor: color.White,
+		FrameWidth: 1,
+	}
+	m.Camera.SetViewPortRect(x, y, w, h)
+	m.Fit(minX, minY, maxX, maxY)
+	return m
+}
+
+// Fit zooms and moves the minimap camera so world rectangle
+// minX, minY to maxX, maxY fills the minimap
+func (m *Minimap) Fit(minX, minY, maxX, maxY float64) {
+	c := m.Camera
+	w, h := math.Abs(maxX-minX), math.Abs(maxY-minY)
+	if w > 0 && h > 0 {
+		c.SetScale(math.Min(c.ViewPort[0]/w, c.ViewPort[1]/h))
+	}
+	c.SetPosition((minX+maxX)/2, (minY+maxY)/2)
+}
+
+// AddMarker adds a marker at world position x, y
+func (m *Minimap) AddMarker(x, y float64, radius float32, clr color.Color) {
+	m.Markers = append(m.Markers, MinimapMarker{Position: f64.Vec2{x, y}, Radius: radius, Color: clr})
+}
+
+// ClearMarkers removes all markers, e.g. before adding this frame's
+func (m *Minimap) ClearMarkers() {
+	m.Markers = m.Markers[:0]
+}
+
+// Draw draws the minimap on `screen`, `drawWorld` draws the world with
+// the minimap camera matrix, and `main` visible area is drawn as a frame,
+// either can be nil
+func (m *Minimap) Draw(screen *ebiten.Image, main *Camera, drawWorld func(target *ebiten.Image, geoM ebiten.GeoM)) {
+	c := m.Camera
+	target := c.Target(screen)
+	if m.Background != nil {
+		x0, y0, x1, y1 := c.viewportRect()
+		vector.DrawFilledRect(target, float32(x0), float32(y0), float32(x1-x0), float32(y1-y0), m.Background, false)
+	}
+	if drawWorld != nil {
+		drawWorld(target, c.GeoM())
+	}
+	if main != nil && m.FrameColor != nil {
+		quad := main.VisibleQuad()
+		for i := range quad {
+			ax, ay := c.WorldToScreen32(quad[i][0], quad[i][1])
+			b := quad[(i+1)%len(quad)]
+			bx, by := c.WorldToScreen32(b[0], b[1])
+			vector.StrokeLine(target, ax, ay, bx, by, m.FrameWidth, m.FrameColor, true)
+		}
+	}
+	for _, mk := range m.Markers {
+		x, y := c.WorldToScreen32(mk.Position[0], mk.Position[1])
+		clr := mk.Color
+		if clr == nil {
+			clr = color.White
+		}
+		vector.DrawFilledCircle(target, x, y, mk.Radius, clr, true)
+	}
+}
+
+// Contains return true when screen position x, y is on the minimap
+func (m *Minimap) Contains(screenX, screenY int) bool {
+	return image.Pt(screenX, screenY).In(m.Camera.ViewPortRect())
+}
+
+// ScreenToWorld return the world position under screen position x, y on
+// the minimap, ok is false when x, y is outside the minimap
+func (m *Minimap) ScreenToWorld(screenX, screenY int) (wx, wy float64, ok bool) {
+	if !m.Contains(screenX, screenY) {
+		return 0, 0, false
+	}
+	wx, wy = m.Camera.ScreenToWorld(screenX, screenY)
+	return wx, wy, !math.IsNaN(wx)
+}
+
+// JumpTo moves `main` to the world position clicked at screen position
+// x, y on the minimap, return false when x, y is outside the minimap
+func (m *Minimap) JumpTo(main *Camera, screenX, screenY int) bool {
+	wx, wy, ok := m.ScreenToWorld(screenX, screenY)
+	if ok {
+		main.SetPosition(wx, wy)
+	}
+	return ok
+}
@@ -0,0 +1,98 @@
+package dango
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/math/f64"
+)
+
+// ParallaxLayer is a background or foreground layer that moves at a
+// different speed to the world seen through a Camera
+type ParallaxLayer struct {
+	Image   *ebiten.Image
+	Scroll  f64.Vec2 // 0 fixed to the viewport, 1 moves with the world, 0.5 half speed
+	Zoom    float64  // 0 ignores camera zoom, 1 zooms with the world
+	Offset  f64.Vec2 // position of the image in the layer
+	RepeatX bool     // tile the image horizontally
+	RepeatY bool     // tile the image vertically
+}
+
+// GeoM return the layer matrix derived from `cam.GeoM()`, it is the camera
+// matrix with scale raised to the power of Zoom, and position moved by
+// Scroll from the point that puts the layer origin at the top left of the
+// viewport, so with Scroll 0 the layer origin stays at the viewport top
+// left, and all layers line up when the camera shows the world origin at
+// the top left. Rotation and shake are the same as the camera.
+func (l *ParallaxLayer) GeoM(cam *Camera) ebiten.GeoM {
+	s := math.Pow(cam.viewScale(), l.Zoom)
+	var pos f64.Vec2
+	for i := range pos {
+		anchor := cam.ViewPort[i] / 2 / s
+		pos[i] = cam.Position[i]*l.Scroll[i] + anchor*(1-l.Scroll[i])
+	}
+	m := ebiten.GeoM{}
+	m.Translate(-pos[0], -pos[1])
+	k := s / cam.viewScale()
+	m.Scale(k, k)
+	m.Translate(cam.Position[0], cam.Position[1])
+	m.Concat(cam.GeoM())
+	return m
+}
+
+// Draw draws the layer on `screen`, clipped to the camera viewport,
+// repeating the image to fill the viewport when RepeatX or RepeatY is set
+func (l *ParallaxLayer) Draw(screen *ebiten.Image, cam *Camera) {
+	if l.Image == nil {
+		return
+	}
+	target := cam.Target(screen)
+	m := l.GeoM(cam)
+	x0, y0, x1, y1 := l.tiles(cam, m)
+	w, h := l.Image.Bounds().Dx(), l.Image.Bounds().Dy()
+	for j := y0; j <= y1; j++ {
+		for i := x0; i <= x1; i++ {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(l.Offset[0]+float64(i*w), l.Offset[1]+float64(j*h))
+			op.GeoM.Concat(m)
+			target.DrawImage(l.Image, op)
+		}
+	}
+}
+
+// tiles return the range of image tiles covering the viewport, 0 to 0 on
+// axes that do not repeat
+func (l *ParallaxLayer) tiles(cam *Camera, m ebiten.GeoM) (x0, y0, x1, y1 int) {
+	if !l.RepeatX && !l.RepeatY {
+		return 0, 0, 0, 0
+	}
+	if !m.IsInvertible() {
+		return 0, 0, 0, 0
+	}
+	m.Invert()
+	sx0, sy0, sx1, sy1 := cam.viewportRect()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range [4]f64.Vec2{{sx0, sy0}, {sx1, sy0}, {sx1, sy1}, {sx0, sy1}} {
+		x, y := m.Apply(p[0], p[1])
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	w, h := float64(l.Image.Bounds().Dx()), float64(l.Image.Bounds().Dy())
+	if l.RepeatX && w > 0 {
+		x0 = int(math.Floor((minX - l.Offset[0]) / w))
+		x1 = int(math.Floor((maxX - l.Offset[0]) / w))
+	}
+	if l.RepeatY && h > 0 {
+		y0 = int(math.Floor((minY - l.Offset[1]) / h))
+		y1 = int(math.Floor((maxY - l.Offset[1]) / h))
+	}
+	return x0, y0, x1, y1
+}
+
+// DrawParallax draws `layers` in order, back to front, through `cam`
+func DrawParallax(screen *ebiten.Image, cam *Camera, layers ...*ParallaxLayer) {
+	for _, l := range layers {
+		l.Draw(screen, cam)
+	}
+}
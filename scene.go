@@ -4,6 +4,10 @@
 package dango
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -12,27 +16,124 @@ type Scene interface {
 	Draw(screen *ebiten.Image)
 }
 
+// Optional lifecycle hooks, SceneManager calls them when a scene implements them
+
+// SceneEnterer is called when the scene is about to be shown,
+// i.e. at the start of the transition into the scene
+type SceneEnterer interface {
+	OnEnter()
+}
+
+// SceneExiter is called when the scene is removed from the SceneManager,
+// i.e. at the end of the transition away from the scene
+type SceneExiter interface {
+	OnExit()
+}
+
+// ScenePauser is called when the scene stops receiving Update
+type ScenePauser interface {
+	OnPause()
+}
+
+// SceneResumer is called when a paused scene receives Update again
+type SceneResumer interface {
+	OnResume()
+}
+
+// SceneLayouter is implemented by scenes that decide the logical screen size,
+// see ebiten.Game Layout
+type SceneLayouter interface {
+	Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int)
+}
+
+// SceneOverlay is implemented by scenes pushed on top of other scenes to
+// control the scenes below. Scenes without it let the scenes below be
+// drawn but not updated, e.g. a pause menu.
+type SceneOverlay interface {
+	UpdateBelow() bool // scenes below keep receiving Update
+	DrawBelow() bool   // scenes below are drawn before this scene
+}
+
+// TransitionPolicy decides what happens when a scene change is requested
+// while a transition is running
+type TransitionPolicy int
+
+const (
+	// TransitionInterrupt drops the pending target and transits to the
+	// new request from the scenes currently shown. Push, Pop and restart
+	// change the pending target, and the transition keeps its remaining
+	// frames.
+	TransitionInterrupt TransitionPolicy = iota
+	// TransitionQueue runs the request after the transition completes
+	TransitionQueue
+	// TransitionIgnore drops the request
+	TransitionIgnore
+)
+
+// ErrSceneNotComparable is returned by Update after a scene change was
+// rejected because a scene type cannot be compared with ==, e.g. a struct
+// with a slice field used as value, use a pointer to the scene instead
+var ErrSceneNotComparable = errors.New("scene type not comparable")
+
 type SceneManager struct {
-	current            Scene
-	next               Scene
+	stack              []Scene // bottom to top, last scene is the current scene
+	next               []Scene // stack after the transition, nil if not transiting
+	paused             []Scene // scenes received OnPause but not OnResume
+	transition         Transition
+	transitionFrames   int        // default number of frames of a transition
+	active             Transition // transition in progress
 	transitionCount    int
 	transitionMaxCount int
 	transitionFrom     *ebiten.Image
 	transitionTo       *ebiten.Image
+	width              int // default logical screen size
+	height             int
+
+	policy         TransitionPolicy
+	queue          []func() // requests waiting for the transition to complete
+	updateOutgoing bool     // update outgoing scenes during a transition
+	updateIncoming bool     // update incoming scenes during a transition
+	onComplete     func(from, to Scene)
+	onError        func(scene Scene, err error) Scene
+
+	factories map[string]*sceneFactory // scene registry, by name
+	named     []namedScene             // scenes created from the registry
+
+	rejected error // scene change rejected since the last Update
 }
 
+var _ ebiten.Game = (*SceneManager)(nil)
+
 // Create new scene manager, where w - screen width, h - screen height,
-// transitionFrames - number of frames to transit between scenes
+// transitionFrames - number of frames to transit between scenes.
+// w and h are returned by Layout unless the current scene implements
+// SceneLayouter, use 0 to follow the window size
 func NewSceneManager(w, h, transitionFrames int) *SceneManager {
-	sm := &SceneManager{transitionMaxCount: transitionFrames}
-	sm.transitionFrom = ebiten.NewImage(w, h)
-	sm.transitionTo = ebiten.NewImage(w, h)
+	sm := &SceneManager{transition: Crossfade{}, transitionFrames: transitionFrames,
+		width: w, height: h}
 	return sm
 }
 
 func (s *SceneManager) Update() error {
-	if s.transitionCount == 0 {
-		return s.current.Update()
+	if err := s.update(); err != nil {
+		return err
+	}
+	err := s.rejected
+	s.rejected = nil
+	return err
+}
+
+func (s *SceneManager) update() error {
+	if !s.IsTransitioning() {
+		return s.updateScenes()
+	}
+
+	if err := s.updateScenes(); err != nil {
+		return err
+	}
+	if !s.IsTransitioning() {
+		// a scene changed the stack during its Update
+		return nil
 	}
 
 	s.transitionCount--
@@ -40,36 +141,419 @@ func (s *SceneManager) Update() error {
 		return nil
 	}
 
-	s.current = s.next
-	s.next = nil
+	s.finishTransition()
 	return nil
 }
 
 func (s *SceneManager) Draw(r *ebiten.Image) {
-	if s.transitionCount == 0 {
-		s.current.Draw(r)
+	if !s.IsTransitioning() {
+		drawScenes(r, s.stack)
 		return
 	}
 
+	s.allocateBuffers(r.Bounds().Dx(), r.Bounds().Dy())
 	s.transitionFrom.Clear()
-	s.current.Draw(s.transitionFrom)
+	drawScenes(s.transitionFrom, s.stack)
 
 	s.transitionTo.Clear()
-	s.next.Draw(s.transitionTo)
+	drawScenes(s.transitionTo, s.next)
+
+	progress := 1 - float64(s.transitionCount)/float64(s.transitionMaxCount)
+	s.active.Draw(r, s.transitionFrom, s.transitionTo, progress)
+}
+
+// Layout return the logical screen size, delegates to the current scene
+// when it implements SceneLayouter
+func (s *SceneManager) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if l, ok := s.Current().(SceneLayouter); ok {
+		return l.Layout(outsideWidth, outsideHeight)
+	}
+	if s.width <= 0 || s.height <= 0 {
+		return outsideWidth, outsideHeight
+	}
+	return s.width, s.height
+}
+
+// allocateBuffers (re)creates the transition images when the screen size changes
+func (s *SceneManager) allocateBuffers(w, h int) {
+	if s.transitionFrom != nil && s.transitionFrom.Bounds().Dx() == w &&
+		s.transitionFrom.Bounds().Dy() == h {
+		return
+	}
+	if s.transitionFrom != nil {
+		s.transitionFrom.Deallocate()
+		s.transitionTo.Deallocate()
+	}
+	s.transitionFrom = ebiten.NewImage(w, h)
+	s.transitionTo = ebiten.NewImage(w, h)
+}
+
+// SetTransition set the default transition used by GoTo and Replace
+func (s *SceneManager) SetTransition(t Transition) {
+	s.transition = t
+}
+
+// SetTransitionPolicy set how scene changes during a transition are handled,
+// default TransitionInterrupt
+func (s *SceneManager) SetTransitionPolicy(p TransitionPolicy) {
+	s.policy = p
+}
+
+// SetTransitionUpdates keep updating the outgoing and/or incoming scenes
+// during a transition, by default neither is updated
+func (s *SceneManager) SetTransitionUpdates(outgoing, incoming bool) {
+	s.apply(func() {
+		s.updateOutgoing = outgoing
+		s.updateIncoming = incoming
+	})
+}
+
+// SetOnTransitionComplete set function `f` called when a transition completes,
+// `from` and `to` are the top scenes before and after the transition
+func (s *SceneManager) SetOnTransitionComplete(f func(from, to Scene)) {
+	s.onComplete = f
+}
+
+// IsTransitioning return true when a transition is running
+func (s *SceneManager) IsTransitioning() bool {
+	return s.next != nil
+}
+
+// Current return the scene on top of the stack, nil if there is none
+func (s *SceneManager) Current() Scene {
+	if len(s.stack) == 0 {
+		return nil
+	}
+	return s.stack[len(s.stack)-1]
+}
+
+// GoTo replaces all scenes with `scene`, with a transition,
+// e.g. GoTo(scene, WithTransition(Slide{}), WithDuration(30))
+func (s *SceneManager) GoTo(scene Scene, opts ...TransitionOption) {
+	if s.deferred(func() { s.GoTo(scene, opts...) }) {
+		return
+	}
+	s.change([]Scene{scene}, s.transitionConfig(opts))
+}
+
+// Replace swaps the top scene with `scene`, with a transition,
+// scenes below are kept
+func (s *SceneManager) Replace(scene Scene, opts ...TransitionOption) {
+	if s.deferred(func() { s.Replace(scene, opts...) }) {
+		return
+	}
+	target := s.target()
+	if len(target) > 0 {
+		target = target[:len(target)-1]
+	}
+	s.change(append(target, scene), s.transitionConfig(opts))
+}
+
+// Push put `scene` on top of the current scene, e.g. a pause menu,
+// see SceneOverlay on how scenes below are updated and drawn
+func (s *SceneManager) Push(scene Scene) {
+	if s.deferred(func() { s.Push(scene) }) {
+		return
+	}
+	s.change(append(s.target(), scene), nil)
+}
+
+// Pop removes the top scene and return it, the last scene is never popped,
+// nil is returned when nothing is popped, or the pop is queued
+func (s *SceneManager) Pop() Scene {
+	if s.deferred(func() { s.Pop() }) {
+		return nil
+	}
+	target := s.target()
+	if len(target) < 2 {
+		return nil
+	}
+	top := target[len(target)-1]
+	s.change(target[:len(target)-1], nil)
+	return top
+}
+
+// deferred return true if request `f` is queued or ignored by the policy
+func (s *SceneManager) deferred(f func()) bool {
+	if !s.IsTransitioning() {
+		return false
+	}
+	switch s.policy {
+	case TransitionQueue:
+		s.queue = append(s.queue, f)
+		return true
+	case TransitionIgnore:
+		return true
+	}
+	return false
+}
+
+// target return a copy of the stack the manager is heading to
+func (s *SceneManager) target() []Scene {
+	if s.IsTransitioning() {
+		return append([]Scene{}, s.next...)
+	}
+	return append([]Scene{}, s.stack...)
+}
+
+// transitionConfig apply `opts` on the default transition
+func (s *SceneManager) transitionConfig(opts []TransitionOption) *transitionConfig {
+	c := &transitionConfig{transition: s.transition, frames: s.transitionFrames}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.transition == nil {
+		c.transition = Crossfade{}
+	}
+	return c
+}
+
+// change the stack to `target`, `transition` is nil for an immediate change,
+// during a transition the pending target is replaced. The change is rejected
+// when a scene is not comparable, see ErrSceneNotComparable.
+func (s *SceneManager) change(target []Scene, transition *transitionConfig) {
+	for _, scene := range target {
+		if err := checkComparable(scene); err != nil {
+			s.rejected = err
+			return
+		}
+	}
+	s.apply(func() {
+		switch {
+		case s.IsTransitioning() && equalScenes(target, s.stack):
+			// going back to the current scenes cancels the transition
+			s.next = nil
+			s.transitionCount = 0
+		case s.IsTransitioning():
+			s.startTransition(target, transition)
+		case transition == nil || transition.frames <= 0 || len(s.stack) == 0:
+			s.stack = target
+		default:
+			s.startTransition(target, transition)
+		}
+	})
+}
+
+func (s *SceneManager) startTransition(target []Scene, transition *transitionConfig) {
+	if transition == nil {
+		// immediate change during a transition keeps its timing
+		s.next = target
+		return
+	}
+	frames := transition.frames
+	if frames <= 0 {
+		frames = 1
+	}
+	s.next = target
+	s.active = transition.transition
+	s.transitionCount = frames
+	s.transitionMaxCount = frames
+}
+
+// finishTransition swaps in the target stack at the end of a transition,
+// then runs queued requests
+func (s *SceneManager) finishTransition() {
+	from := s.Current()
+	s.apply(func() {
+		s.stack = s.next
+		s.next = nil
+		s.transitionCount = 0
+	})
+	if s.onComplete != nil {
+		s.onComplete(from, s.Current())
+	}
+	for len(s.queue) > 0 && !s.IsTransitioning() {
+		f := s.queue[0]
+		s.queue = s.queue[1:]
+		f()
+	}
+}
+
+// apply runs `f` that changes the scenes, then calls lifecycle hooks
+// on scenes that are added, removed, paused or resumed
+func (s *SceneManager) apply(f func()) {
+	beforeScenes := s.scenes()
+	beforeUpdating := s.updating()
+	f()
+	afterScenes := s.scenes()
+	afterUpdating := s.updating()
 
-	r.DrawImage(s.transitionFrom, nil)
+	for _, scene := range beforeUpdating {
+		if !containsScene(afterUpdating, scene) {
+			s.paused = append(s.paused, scene)
+			pauseScene(scene)
+		}
+	}
+	for _, scene := range afterScenes {
+		if !containsScene(beforeScenes, scene) {
+			enterScene(scene)
+		}
+	}
+	for _, scene := range beforeScenes {
+		if !containsScene(afterScenes, scene) {
+			s.paused = removeScene(s.paused, scene)
+			exitScene(scene)
+		}
+	}
+	for _, scene := range afterUpdating {
+		if containsScene(s.paused, scene) {
+			s.paused = removeScene(s.paused, scene)
+			resumeScene(scene)
+		}
+	}
+	s.pruneNames()
+}
 
-	alpha := 1 - float32(s.transitionCount)/float32(s.transitionMaxCount)
-	op := &ebiten.DrawImageOptions{}
-	op.ColorScale.Scale(1, 1, 1, alpha)
-	r.DrawImage(s.transitionTo, op)
+// scenes return all scenes in the stack and the transition target
+func (s *SceneManager) scenes() []Scene {
+	scenes := append([]Scene{}, s.stack...)
+	for _, scene := range s.next {
+		if !containsScene(scenes, scene) {
+			scenes = append(scenes, scene)
+		}
+	}
+	return scenes
 }
 
-func (s *SceneManager) GoTo(scene Scene) {
-	if s.current == nil {
-		s.current = scene
-	} else {
-		s.next = scene
-		s.transitionCount = s.transitionMaxCount
+// updateScenes calls Update on scenes that receive it, bottom to top. When a
+// scene changes the stack, scenes above it that no longer receive Update,
+// e.g. exited, are skipped.
+func (s *SceneManager) updateScenes() error {
+	for _, scene := range s.updating() {
+		if !containsScene(s.updating(), scene) {
+			continue
+		}
+		if err := scene.Update(); err != nil {
+			return s.handleError(scene, err)
+		}
+	}
+	return nil
+}
+
+// updating return scenes that receive Update, bottom to top
+func (s *SceneManager) updating() []Scene {
+	if !s.IsTransitioning() {
+		return updatedScenes(s.stack)
+	}
+	var scenes []Scene
+	if s.updateOutgoing {
+		scenes = append(scenes, updatedScenes(s.stack)...)
+	}
+	if s.updateIncoming {
+		for _, scene := range updatedScenes(s.next) {
+			if !containsScene(scenes, scene) {
+				scenes = append(scenes, scene)
+			}
+		}
+	}
+	return scenes
+}
+
+// updatedScenes return scenes that receive Update, bottom to top
+func updatedScenes(stack []Scene) []Scene {
+	if len(stack) == 0 {
+		return nil
+	}
+	i := len(stack) - 1
+	for i > 0 {
+		o, ok := stack[i].(SceneOverlay)
+		if !ok || !o.UpdateBelow() {
+			break
+		}
+		i--
+	}
+	return stack[i:]
+}
+
+// drawScenes draws visible scenes, bottom to top
+func drawScenes(screen *ebiten.Image, stack []Scene) {
+	if len(stack) == 0 {
+		return
+	}
+	i := len(stack) - 1
+	for i > 0 {
+		if o, ok := stack[i].(SceneOverlay); ok && !o.DrawBelow() {
+			break
+		}
+		i--
+	}
+	for _, scene := range stack[i:] {
+		scene.Draw(screen)
+	}
+}
+
+func enterScene(scene Scene) {
+	if e, ok := scene.(SceneEnterer); ok {
+		e.OnEnter()
+	}
+}
+
+func exitScene(scene Scene) {
+	if e, ok := scene.(SceneExiter); ok {
+		e.OnExit()
+	}
+}
+
+func pauseScene(scene Scene) {
+	if p, ok := scene.(ScenePauser); ok {
+		p.OnPause()
+	}
+}
+
+func resumeScene(scene Scene) {
+	if r, ok := scene.(SceneResumer); ok {
+		r.OnResume()
+	}
+}
+
+// checkComparable return ErrSceneNotComparable when `scene` cannot be
+// compared with ==, scenes in the stack are told apart by comparing them
+func checkComparable(scene Scene) error {
+	if scene != nil && !reflect.TypeOf(scene).Comparable() {
+		return fmt.Errorf("%w: %T, use a pointer to the scene", ErrSceneNotComparable, scene)
+	}
+	return nil
+}
+
+// sameScene compares two scenes without panicking on non-comparable types,
+// these never match but are not in the stack, see checkComparable
+func sameScene(a, b Scene) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+func containsScene(stack []Scene, scene Scene) bool {
+	for _, s := range stack {
+		if sameScene(s, scene) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeScene(stack []Scene, scene Scene) []Scene {
+	kept := stack[:0]
+	for _, s := range stack {
+		if !sameScene(s, scene) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+func equalScenes(a, b []Scene) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameScene(a[i], b[i]) {
+			return false
+		}
 	}
+	return true
 }
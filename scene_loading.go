@@ -0,0 +1,208 @@
+package dango
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"runtime"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// LoadTask is a named loading function run by LoadingScene in the background,
+// e.g. loading images with FS.GetImage
+type LoadTask struct {
+	Name string
+	Load func() error
+}
+
+// LoadProgress of a LoadingScene
+type LoadProgress struct {
+	Done    int     // number of finished tasks, including failed tasks
+	Total   int     // number of tasks
+	Current string  // name of the latest started task
+	Errors  []error // errors of failed tasks
+}
+
+// Fraction return finished tasks from 0 to 1, e.g. for a progress bar
+func (p LoadProgress) Fraction() float64 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float64(p.Done) / float64(p.Total)
+}
+
+// Finished return true when all tasks are done
+func (p LoadProgress) Finished() bool {
+	return p.Done >= p.Total
+}
+
+// LoadingScene runs load tasks in background goroutines, then goes to the
+// target scene through the SceneManager, or to the error scene if any task fails
+type LoadingScene struct {
+	manager *SceneManager
+	tasks   []LoadTask
+	target  func() (Scene, error)
+	onError func(errs []error) Scene
+	draw    func(screen *ebiten.Image, p LoadProgress)
+	opts    []TransitionOption
+	workers int
+
+	mu       sync.Mutex
+	progress LoadProgress
+	started  bool
+	done     bool // navigated away
+}
+
+// NewLoadingScene create a loading scene running `tasks`, `target` is called
+// after all tasks succeed to create the next scene
+func NewLoadingScene(sm *SceneManager, target func() (Scene, error), tasks ...LoadTask) *LoadingScene {
+	return &LoadingScene{
+		manager:  sm,
+		tasks:    tasks,
+		target:   target,
+		workers:  runtime.NumCPU(),
+		progress: LoadProgress{Total: len(tasks)},
+	}
+}
+
+// SetErrorScene set function creating the scene shown when loading fails,
+// without it, or when it returns nil, Update return the errors
+func (l *LoadingScene) SetErrorScene(f func(errs []error) Scene) {
+	l.onError = f
+}
+
+// SetWorkers set number of goroutines running tasks, default number of CPU
+func (l *LoadingScene) SetWorkers(n int) {
+	l.workers = n
+}
+
+// SetDraw replaces the default progress bar
+func (l *LoadingScene) SetDraw(f func(screen *ebiten.Image, p LoadProgress)) {
+	l.draw = f
+}
+
+// SetTransitionOptions set options of the transition away from the loading scene
+func (l *LoadingScene) SetTransitionOptions(opts ...TransitionOption) {
+	l.opts = opts
+}
+
+// Progress return a copy of the current progress, safe to call at any time
+func (l *LoadingScene) Progress() LoadProgress {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p := l.progress
+	p.Errors = append([]error{}, l.progress.Errors...)
+	return p
+}
+
+// OnEnter starts loading when the SceneManager shows the scene
+func (l *LoadingScene) OnEnter() {
+	l.start()
+}
+
+func (l *LoadingScene) start() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.started {
+		return
+	}
+	l.started = true
+
+	workers := l.workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(l.tasks) {
+		workers = len(l.tasks)
+	}
+	queue := make(chan LoadTask, len(l.tasks))
+	for _, t := range l.tasks {
+		queue <- t
+	}
+	close(queue)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for t := range queue {
+				l.run(t)
+			}
+		}()
+	}
+}
+
+// run a task, a panic in the task is reported as an error
+func (l *LoadingScene) run(t LoadTask) {
+	l.mu.Lock()
+	l.progress.Current = t.Name
+	l.mu.Unlock()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return t.Load()
+	}()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		l.progress.Errors = append(l.progress.Errors, fmt.Errorf("load %s: %w", t.Name, err))
+	}
+	l.progress.Done++
+}
+
+func (l *LoadingScene) Update() error {
+	l.start()
+	if l.done {
+		return nil
+	}
+	p := l.Progress()
+	if !p.Finished() {
+		return nil
+	}
+	l.done = true
+
+	errs := p.Errors
+	if len(errs) == 0 {
+		next, err := l.target()
+		if err == nil {
+			l.leave(next)
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	var next Scene
+	if l.onError != nil {
+		next = l.onError(errs)
+	}
+	if next == nil {
+		return errors.Join(errs...)
+	}
+	l.leave(next)
+	return nil
+}
+
+// leave goes to `next`, it is not subject to the transition policy, so a
+// loading scene updated during its own transition is not left behind
+func (l *LoadingScene) leave(next Scene) {
+	sm := l.manager
+	sm.change([]Scene{next}, sm.transitionConfig(l.opts))
+}
+
+func (l *LoadingScene) Draw(screen *ebiten.Image) {
+	p := l.Progress()
+	if l.draw != nil {
+		l.draw(screen, p)
+		return
+	}
+	// default progress bar at the centre of the screen
+	w, h := screenSize(screen)
+	barW, barH := float32(w*0.6), float32(12)
+	x, y := float32(w/2)-barW/2, float32(h/2)-barH/2
+	vector.StrokeRect(screen, x, y, barW, barH, 1, color.White, false)
+	vector.DrawFilledRect(screen, x+2, y+2, (barW-4)*float32(p.Fraction()), barH-4, color.White, false)
+}
@@ -0,0 +1,198 @@
+package dango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrSceneNotRegistered is returned when navigating to an unknown scene name
+var ErrSceneNotRegistered = errors.New("scene not registered")
+
+// SceneParams are passed to a SceneFactory, e.g. {"level": 3}
+type SceneParams map[string]any
+
+// SceneFactory creates a scene from `params`. Params are the values passed
+// when navigating, or values decoded from JSON when a snapshot is restored,
+// where numbers are float64 and structs are maps, read numbers with
+// SceneParams.Int and SceneParams.Float to handle both.
+type SceneFactory func(params SceneParams) (Scene, error)
+
+// Int return number param `key` as int, 0 if missing or not a number
+func (p SceneParams) Int(key string) int {
+	switch v := p[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case json.Number:
+		i, _ := v.Int64()
+		return int(i)
+	}
+	return 0
+}
+
+// Float return number param `key` as float64, 0 if missing or not a number
+func (p SceneParams) Float(key string) float64 {
+	switch v := p[key].(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return 0
+}
+
+type sceneFactory struct {
+	create SceneFactory
+	cached bool
+	scene  Scene       // cached instance
+	params SceneParams // params the cached instance was created with
+}
+
+// namedScene remembers how a scene in the stack was created
+type namedScene struct {
+	scene  Scene
+	name   string
+	params SceneParams
+}
+
+// Register scene factory as `name`, a new scene is created every time
+// the scene is navigated to
+func (s *SceneManager) Register(name string, factory SceneFactory) {
+	s.register(name, factory, false)
+}
+
+// RegisterCached registers scene factory as `name`, the scene is created
+// once and reused, `params` are only used when the scene is created
+func (s *SceneManager) RegisterCached(name string, factory SceneFactory) {
+	s.register(name, factory, true)
+}
+
+func (s *SceneManager) register(name string, factory SceneFactory, cached bool) {
+	if s.factories == nil {
+		s.factories = map[string]*sceneFactory{}
+	}
+	s.factories[name] = &sceneFactory{create: factory, cached: cached}
+}
+
+// ClearCache drops the cached scene of `name`, it is created fresh
+// the next time it is navigated to
+func (s *SceneManager) ClearCache(name string) {
+	if f, ok := s.factories[name]; ok {
+		f.scene = nil
+		f.params = nil
+	}
+}
+
+// NewScene return a scene registered as `name`, the cached instance
+// is returned for scenes registered with RegisterCached
+func (s *SceneManager) NewScene(name string, params SceneParams) (Scene, error) {
+	f, ok := s.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSceneNotRegistered, name)
+	}
+	scene := f.scene
+	if scene == nil {
+		var err error
+		scene, err = f.create(params)
+		if err != nil {
+			return nil, fmt.Errorf("create scene %s: %w", name, err)
+		}
+		if err := checkComparable(scene); err != nil {
+			return nil, fmt.Errorf("create scene %s: %w", name, err)
+		}
+		if f.cached {
+			f.scene = scene
+			f.params = params
+		}
+	} else {
+		// cached scene keeps the params it was created with
+		params = f.params
+	}
+	s.addName(namedScene{scene: scene, name: name, params: params})
+	return scene, nil
+}
+
+// GoToNamed replaces all scenes with scene registered as `name`
+func (s *SceneManager) GoToNamed(name string, params SceneParams, opts ...TransitionOption) error {
+	scene, err := s.NewScene(name, params)
+	if err != nil {
+		return err
+	}
+	s.navigateNamed(scene, func() { s.GoTo(scene, opts...) })
+	return nil
+}
+
+// ReplaceNamed swaps the top scene with scene registered as `name`
+func (s *SceneManager) ReplaceNamed(name string, params SceneParams, opts ...TransitionOption) error {
+	scene, err := s.NewScene(name, params)
+	if err != nil {
+		return err
+	}
+	s.navigateNamed(scene, func() { s.Replace(scene, opts...) })
+	return nil
+}
+
+// PushNamed put scene registered as `name` on top of the current scene
+func (s *SceneManager) PushNamed(name string, params SceneParams) error {
+	scene, err := s.NewScene(name, params)
+	if err != nil {
+		return err
+	}
+	s.navigateNamed(scene, func() { s.Push(scene) })
+	return nil
+}
+
+// navigateNamed runs `f` navigating to `scene` from NewScene. A queued
+// request's scene is not in the stack while waiting, so its name is pruned,
+// the name is recorded again when the request runs.
+func (s *SceneManager) navigateNamed(scene Scene, f func()) {
+	name, params := s.SceneName(scene)
+	if s.deferred(func() {
+		s.addName(namedScene{scene: scene, name: name, params: params})
+		f()
+	}) {
+		return
+	}
+	f()
+}
+
+// addName records how `n.scene` was created, unless it is already known
+func (s *SceneManager) addName(n namedScene) {
+	if name, _ := s.SceneName(n.scene); name == "" {
+		s.named = append(s.named, n)
+	}
+}
+
+// SceneName return the registered name of `scene`, and the params it was
+// created with, empty name if the scene was not created from the registry
+func (s *SceneManager) SceneName(scene Scene) (string, SceneParams) {
+	for i := len(s.named) - 1; i >= 0; i-- {
+		if sameScene(s.named[i].scene, scene) {
+			return s.named[i].name, s.named[i].params
+		}
+	}
+	return "", nil
+}
+
+// pruneNames forgets scenes no longer in the stack
+func (s *SceneManager) pruneNames() {
+	kept := s.named[:0]
+	for _, n := range s.named {
+		if containsScene(s.stack, n.scene) || containsScene(s.next, n.scene) {
+			kept = append(kept, n)
+		}
+	}
+	for i := len(kept); i < len(s.named); i++ {
+		s.named[i] = namedScene{}
+	}
+	s.named = kept
+}
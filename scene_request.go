@@ -0,0 +1,153 @@
+package dango
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type requestKind int
+
+const (
+	requestGoTo requestKind = iota
+	requestGoToNamed
+	requestPush
+	requestPop
+	requestRestart
+	requestQuit
+)
+
+// SceneRequest is returned as error from Scene.Update to ask the SceneManager
+// to change scenes, e.g. `return dango.GoToScene(menu)` or `return dango.ErrQuit`
+type SceneRequest struct {
+	kind   requestKind
+	scene  Scene
+	name   string
+	params SceneParams
+	opts   []TransitionOption
+}
+
+func (r *SceneRequest) Error() string {
+	switch r.kind {
+	case requestGoTo:
+		return fmt.Sprintf("scene request: go to %v", r.scene)
+	case requestGoToNamed:
+		return fmt.Sprintf("scene request: go to %s", r.name)
+	case requestPush:
+		return fmt.Sprintf("scene request: push %v", r.scene)
+	case requestPop:
+		return "scene request: pop"
+	case requestRestart:
+		return "scene request: restart"
+	default:
+		return "scene request: quit"
+	}
+}
+
+var (
+	// ErrPopScene pops the top scene
+	ErrPopScene error = &SceneRequest{kind: requestPop}
+	// ErrRestartScene restarts the scene returning it, scenes from the
+	// registry are created again with the same params, others are exited
+	// and entered again
+	ErrRestartScene error = &SceneRequest{kind: requestRestart}
+	// ErrQuit exits all scenes, then SceneManager.Update return ebiten.Termination
+	ErrQuit error = &SceneRequest{kind: requestQuit}
+)
+
+// GoToScene return a request to go to `scene`
+func GoToScene(scene Scene, opts ...TransitionOption) error {
+	return &SceneRequest{kind: requestGoTo, scene: scene, opts: opts}
+}
+
+// GoToNamedScene return a request to go to the scene registered as `name`
+func GoToNamedScene(name string, params SceneParams, opts ...TransitionOption) error {
+	return &SceneRequest{kind: requestGoToNamed, name: name, params: params, opts: opts}
+}
+
+// PushScene return a request to push `scene` on top of the current scene
+func PushScene(scene Scene) error {
+	return &SceneRequest{kind: requestPush, scene: scene}
+}
+
+// SetErrorHandler set function `f` called with unexpected errors returned by
+// `scene`, the returned scene replaces all scenes, e.g. a crash report scene.
+// Return nil to end the game with the error.
+func (s *SceneManager) SetErrorHandler(f func(scene Scene, err error) Scene) {
+	s.onError = f
+}
+
+// handleError of Update from `scene`
+func (s *SceneManager) handleError(scene Scene, err error) error {
+	var req *SceneRequest
+	if errors.As(err, &req) {
+		return s.request(scene, req)
+	}
+	if errors.Is(err, ebiten.Termination) || s.onError == nil {
+		return err
+	}
+	next := s.onError(scene, err)
+	if next == nil {
+		return err
+	}
+	// error scene is not subject to the transition policy
+	s.change([]Scene{next}, s.transitionConfig(nil))
+	return nil
+}
+
+func (s *SceneManager) request(scene Scene, req *SceneRequest) error {
+	switch req.kind {
+	case requestGoTo:
+		s.GoTo(req.scene, req.opts...)
+	case requestGoToNamed:
+		if err := s.GoToNamed(req.name, req.params, req.opts...); err != nil {
+			return s.handleError(scene, err)
+		}
+	case requestPush:
+		s.Push(req.scene)
+	case requestPop:
+		s.Pop()
+	case requestRestart:
+		return s.restart(scene)
+	case requestQuit:
+		s.queue = nil
+		s.apply(func() {
+			s.stack = nil
+			s.next = nil
+			s.transitionCount = 0
+		})
+		return ebiten.Termination
+	}
+	return nil
+}
+
+// restart `scene`, see ErrRestartScene
+func (s *SceneManager) restart(scene Scene) error {
+	if s.deferred(func() { s.restart(scene) }) {
+		return nil
+	}
+	name, params := s.SceneName(scene)
+	if name == "" {
+		exitScene(scene)
+		enterScene(scene)
+		return nil
+	}
+	s.ClearCache(name)
+	fresh, err := s.NewScene(name, params)
+	if err != nil {
+		return s.handleError(scene, err)
+	}
+	target := s.target()
+	for i := range target {
+		if sameScene(target[i], scene) {
+			target[i] = fresh
+		}
+	}
+	var transition *transitionConfig
+	if !s.IsTransitioning() {
+		transition = s.transitionConfig(nil)
+	}
+	s.change(target, transition)
+	return nil
+}
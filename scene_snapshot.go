@@ -0,0 +1,104 @@
+package dango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// StatefulScene is implemented by scenes that save and restore their state
+// in SceneManager snapshots
+type StatefulScene interface {
+	SaveState() ([]byte, error)
+	RestoreState(data []byte) error
+}
+
+// ErrSceneNotNamed is returned when a snapshot contains a scene that was not
+// created from the scene registry
+var ErrSceneNotNamed = errors.New("scene not created from registry")
+
+// snapshotVersion of the snapshot format written by Snapshot
+const snapshotVersion = 1
+
+type sceneSnapshot struct {
+	Version int             `json:"version"`
+	Scenes  []snapshotScene `json:"scenes"` // bottom to top
+}
+
+type snapshotScene struct {
+	Name   string      `json:"name"`
+	Params SceneParams `json:"params,omitempty"`
+	State  []byte      `json:"state,omitempty"`
+}
+
+// Snapshot return the scene stack, names, params and states of scenes
+// implementing StatefulScene, as a versioned blob. All scenes must be created
+// from the registry, e.g. with GoToNamed, params must be JSON serialisable.
+// During a transition the target scenes are saved.
+func (s *SceneManager) Snapshot() ([]byte, error) {
+	snap := sceneSnapshot{Version: snapshotVersion}
+	for _, scene := range s.target() {
+		name, params := s.SceneName(scene)
+		if name == "" {
+			return nil, fmt.Errorf("%w: %v", ErrSceneNotNamed, scene)
+		}
+		ss := snapshotScene{Name: name, Params: params}
+		if st, ok := scene.(StatefulScene); ok {
+			state, err := st.SaveState()
+			if err != nil {
+				return nil, fmt.Errorf("save scene %s: %w", name, err)
+			}
+			ss.State = state
+		}
+		snap.Scenes = append(snap.Scenes, ss)
+	}
+	return json.Marshal(snap)
+}
+
+// Restore replaces all scenes with scenes from `data` created by Snapshot,
+// without transition. Params are passed to factories as decoded from JSON,
+// see SceneFactory.
+func (s *SceneManager) Restore(data []byte) error {
+	var snap sceneSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	if snap.Version < 1 || snap.Version > snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	if len(snap.Scenes) == 0 {
+		return errors.New("snapshot has no scenes")
+	}
+
+	stack := []Scene{}
+	for _, ss := range snap.Scenes {
+		scene, err := s.NewScene(ss.Name, ss.Params)
+		if err != nil {
+			return err
+		}
+		if st, ok := scene.(StatefulScene); ok && ss.State != nil {
+			if err := st.RestoreState(ss.State); err != nil {
+				return fmt.Errorf("restore scene %s: %w", ss.Name, err)
+			}
+		}
+		stack = append(stack, scene)
+	}
+
+	s.queue = nil
+	s.apply(func() {
+		s.stack = stack
+		s.next = nil
+		s.transitionCount = 0
+	})
+	return nil
+}
+
+// RestoreFS restores scenes from snapshot file `path`, e.g. from dango.FS
+func (s *SceneManager) RestoreFS(fsys fs.ReadFileFS, path string) error {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return s.Restore(data)
+}
@@ -0,0 +1,736 @@
+package dango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testScene records lifecycle calls
+type testScene struct {
+	name    string
+	updates int
+	events  []string
+}
+
+func (s *testScene) Update() error {
+	s.updates++
+	return nil
+}
+
+func (s *testScene) Draw(screen *ebiten.Image) {}
+
+func (s *testScene) OnEnter()  { s.events = append(s.events, "enter") }
+func (s *testScene) OnExit()   { s.events = append(s.events, "exit") }
+func (s *testScene) OnPause()  { s.events = append(s.events, "pause") }
+func (s *testScene) OnResume() { s.events = append(s.events, "resume") }
+
+func (s *testScene) String() string {
+	return s.name
+}
+
+func equalEvents(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSceneLifecycle(t *testing.T) {
+	a := &testScene{name: "a"}
+	b := &testScene{name: "b"}
+	sm := NewSceneManager(10, 10, 3)
+
+	sm.GoTo(a)
+	if !equalEvents(a.events, []string{"enter"}) {
+		t.Fatalf("Expect [enter], got %v", a.events)
+	}
+
+	sm.GoTo(b)
+	if !equalEvents(a.events, []string{"enter", "pause"}) {
+		t.Errorf("Expect [enter pause], got %v", a.events)
+	}
+	if !equalEvents(b.events, []string{"enter"}) {
+		t.Errorf("Expect [enter], got %v", b.events)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := sm.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !equalEvents(a.events, []string{"enter", "pause", "exit"}) {
+		t.Errorf("Expect [enter pause exit], got %v", a.events)
+	}
+	if a.updates != 0 || b.updates != 0 {
+		t.Errorf("Expect no update during transition, got %d, %d", a.updates, b.updates)
+	}
+
+	sm.Update()
+	if b.updates != 1 {
+		t.Errorf("Expect 1 update, got %d", b.updates)
+	}
+}
+
+// valueScene is not comparable, it has a slice field and value receivers
+type valueScene struct {
+	events []string
+}
+
+func (s valueScene) Update() error             { return nil }
+func (s valueScene) Draw(screen *ebiten.Image) {}
+
+func TestSceneNotComparable(t *testing.T) {
+	sm := NewSceneManager(10, 10, 0)
+	sm.GoTo(valueScene{})
+	if sm.Current() != nil {
+		t.Errorf("Expect no scene, got %v", sm.Current())
+	}
+	if err := sm.Update(); !errors.Is(err, ErrSceneNotComparable) {
+		t.Errorf("Expect ErrSceneNotComparable, got %v", err)
+	}
+	if err := sm.Update(); err != nil {
+		t.Errorf("Expect error returned once, got %v", err)
+	}
+
+	a := &testScene{name: "a"}
+	sm.GoTo(a)
+	sm.Push(valueScene{})
+	if sm.Current() != a || !equalEvents(a.events, []string{"enter"}) {
+		t.Errorf("Expect a unchanged, got %v %v", sm.Current(), a.events)
+	}
+	if err := sm.Update(); !errors.Is(err, ErrSceneNotComparable) {
+		t.Errorf("Expect ErrSceneNotComparable, got %v", err)
+	}
+	if a.updates != 1 {
+		t.Errorf("Expect 1 update, got %d", a.updates)
+	}
+
+	sm.Register("value", func(p SceneParams) (Scene, error) { return valueScene{}, nil })
+	if err := sm.GoToNamed("value", nil); !errors.Is(err, ErrSceneNotComparable) {
+		t.Errorf("Expect ErrSceneNotComparable, got %v", err)
+	}
+}
+
+func TestSceneGoBackDuringTransition(t *testing.T) {
+	a := &testScene{name: "a"}
+	b := &testScene{name: "b"}
+	sm := NewSceneManager(10, 10, 3)
+	sm.GoTo(a)
+	sm.GoTo(b)
+	sm.Update()
+	sm.GoTo(a)
+	if !equalEvents(a.events, []string{"enter", "pause", "resume"}) {
+		t.Errorf("Expect [enter pause resume], got %v", a.events)
+	}
+	if !equalEvents(b.events, []string{"enter", "exit"}) {
+		t.Errorf("Expect [enter exit], got %v", b.events)
+	}
+	sm.Update()
+	if a.updates != 1 {
+		t.Errorf("Expect 1 update, got %d", a.updates)
+	}
+}
+
+// testOverlay is a scene drawn on top of others
+type testOverlay struct {
+	testScene
+	updateBelow bool
+	drawBelow   bool
+}
+
+func (o *testOverlay) UpdateBelow() bool { return o.updateBelow }
+func (o *testOverlay) DrawBelow() bool   { return o.drawBelow }
+
+func TestScenePushPop(t *testing.T) {
+	game := &testScene{name: "game"}
+	menu := &testScene{name: "menu"}
+	sm := NewSceneManager(10, 10, 3)
+	sm.GoTo(game)
+
+	sm.Push(menu)
+	if sm.Current() != menu {
+		t.Fatalf("Expect menu on top, got %v", sm.Current())
+	}
+	if !equalEvents(game.events, []string{"enter", "pause"}) {
+		t.Errorf("Expect [enter pause], got %v", game.events)
+	}
+	sm.Update()
+	if game.updates != 0 || menu.updates != 1 {
+		t.Errorf("Expect updates 0 and 1, got %d and %d", game.updates, menu.updates)
+	}
+
+	if popped := sm.Pop(); popped != menu {
+		t.Errorf("Expect menu popped, got %v", popped)
+	}
+	if !equalEvents(game.events, []string{"enter", "pause", "resume"}) {
+		t.Errorf("Expect [enter pause resume], got %v", game.events)
+	}
+	if !equalEvents(menu.events, []string{"enter", "pause", "exit"}) {
+		t.Errorf("Expect [enter pause exit], got %v", menu.events)
+	}
+	if sm.Pop() != nil {
+		t.Errorf("Expect last scene not popped")
+	}
+
+	hud := &testOverlay{testScene: testScene{name: "hud"}, updateBelow: true}
+	sm.Push(hud)
+	sm.Update()
+	if game.updates != 1 || hud.updates != 1 {
+		t.Errorf("Expect updates 1 and 1, got %d and %d", game.updates, hud.updates)
+	}
+	if !equalEvents(game.events, []string{"enter", "pause", "resume"}) {
+		t.Errorf("Expect game not paused, got %v", game.events)
+	}
+}
+
+func TestSceneReplace(t *testing.T) {
+	game := &testScene{name: "game"}
+	menu := &testScene{name: "menu"}
+	options := &testScene{name: "options"}
+	sm := NewSceneManager(10, 10, 2)
+	sm.GoTo(game)
+	sm.Push(menu)
+	sm.Replace(options)
+	sm.Update()
+	sm.Update()
+	if sm.Current() != options {
+		t.Fatalf("Expect options on top, got %v", sm.Current())
+	}
+	if !equalEvents(menu.events, []string{"enter", "pause", "exit"}) {
+		t.Errorf("Expect [enter pause exit], got %v", menu.events)
+	}
+	sm.Pop()
+	if sm.Current() != game {
+		t.Errorf("Expect game on top, got %v", sm.Current())
+	}
+}
+
+func TestSceneTransitionDuration(t *testing.T) {
+	a := &testScene{name: "a"}
+	b := &testScene{name: "b"}
+	sm := NewSceneManager(10, 10, 30)
+	sm.GoTo(a)
+	sm.GoTo(b, WithTransition(Slide{Direction: DirectionLeft, Ease: EaseInOutQuad}), WithDuration(2))
+	sm.Update()
+	if sm.Current() != a {
+		t.Errorf("Expect a during transition, got %v", sm.Current())
+	}
+	sm.Update()
+	if sm.Current() != b {
+		t.Errorf("Expect b after 2 frames, got %v", sm.Current())
+	}
+}
+
+func TestTransitionDraw(t *testing.T) {
+	transitions := []Transition{
+		Crossfade{},
+		FadeThrough{Ease: EaseInOutSine},
+		Slide{Direction: DirectionUp, Push: true},
+		Wipe{Direction: DirectionLeft},
+		Wipe{Direction: DirectionRight},
+		Wipe{Direction: DirectionUp},
+		Wipe{Direction: DirectionDown, Ease: EaseOutBack},
+		&Iris{},
+		&Iris{Close: true},
+		&Pixelate{MaxBlock: 8},
+	}
+	for _, size := range []int{64, 48} {
+		screen := ebiten.NewImage(size, size)
+		from := ebiten.NewImage(size, size)
+		to := ebiten.NewImage(size, size)
+		from.Fill(color.White)
+		for _, tr := range transitions {
+			for _, p := range []float64{0, 0.25, 0.5, 1} {
+				screen.Clear()
+				tr.Draw(screen, from, to, p)
+			}
+		}
+	}
+	px := &Pixelate{MaxBlock: 8}
+	px.Draw(ebiten.NewImage(32, 16), ebiten.NewImage(32, 16), ebiten.NewImage(32, 16), 0.5)
+	if px.buffer == nil || px.buffer.Bounds().Dx() != 32 {
+		t.Errorf("Expect pixelate buffer resized to the screen, got %v", px.buffer)
+	}
+}
+
+func TestEasing(t *testing.T) {
+	curves := []Easing{EaseLinear, EaseInQuad, EaseOutQuad, EaseInOutQuad,
+		EaseInCubic, EaseOutCubic, EaseInOutCubic, EaseInOutSine, EaseOutBack}
+	for i, e := range curves {
+		if !EqualFloat(e(0), 0, 1e-9) || !EqualFloat(e(1), 1, 1e-9) {
+			t.Errorf("Expect curve %d from 0 to 1, got %f to %f", i, e(0), e(1))
+		}
+	}
+}
+
+func TestSceneRegistry(t *testing.T) {
+	sm := NewSceneManager(10, 10, 0)
+	created := 0
+	sm.Register("level", func(params SceneParams) (Scene, error) {
+		created++
+		return &testScene{name: fmt.Sprintf("level%v", params["id"])}, nil
+	})
+	sm.RegisterCached("menu", func(params SceneParams) (Scene, error) {
+		created++
+		return &testScene{name: "menu"}, nil
+	})
+
+	if err := sm.GoToNamed("level", SceneParams{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sm.Current().(*testScene).name; got != "level1" {
+		t.Errorf("Expect level1, got %s", got)
+	}
+	if name, params := sm.SceneName(sm.Current()); name != "level" || params["id"] != 1 {
+		t.Errorf("Expect level with id 1, got %s %v", name, params)
+	}
+
+	menu, _ := sm.NewScene("menu", nil)
+	sm.GoToNamed("menu", nil)
+	sm.GoToNamed("level", SceneParams{"id": 1})
+	sm.GoToNamed("menu", nil)
+	if sm.Current() != menu {
+		t.Errorf("Expect cached menu scene")
+	}
+	if created != 3 {
+		t.Errorf("Expect 3 scenes created, got %d", created)
+	}
+
+	if err := sm.GoToNamed("missing", nil); !errors.Is(err, ErrSceneNotRegistered) {
+		t.Errorf("Expect ErrSceneNotRegistered, got %v", err)
+	}
+}
+
+func TestSceneRegistryQueued(t *testing.T) {
+	sm := NewSceneManager(10, 10, 2)
+	sm.SetTransitionPolicy(TransitionQueue)
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		sm.Register(name, func(params SceneParams) (Scene, error) {
+			return &testScene{name: name}, nil
+		})
+	}
+	sm.RegisterCached("menu", func(params SceneParams) (Scene, error) {
+		return &testScene{name: "menu"}, nil
+	})
+
+	sm.GoToNamed("a", nil)
+	sm.GoToNamed("b", nil)
+	sm.GoToNamed("c", SceneParams{"id": 3}) // queued until b is shown
+	for i := 0; i < 6; i++ {
+		sm.Update()
+	}
+	if got := sm.Current().(*testScene).name; got != "c" {
+		t.Fatalf("Expect c, got %s", got)
+	}
+	if name, params := sm.SceneName(sm.Current()); name != "c" || params["id"] != 3 {
+		t.Errorf("Expect c with id 3, got %q %v", name, params)
+	}
+	if _, err := sm.Snapshot(); err != nil {
+		t.Errorf("Expect snapshot of queued named scene, got %v", err)
+	}
+
+	// cached scene keeps the params it was created with
+	sm.GoToNamed("menu", SceneParams{"id": 1})
+	for i := 0; i < 3; i++ {
+		sm.Update()
+	}
+	sm.GoToNamed("a", nil)
+	for i := 0; i < 3; i++ {
+		sm.Update()
+	}
+	sm.GoToNamed("menu", SceneParams{"id": 2})
+	for i := 0; i < 3; i++ {
+		sm.Update()
+	}
+	if name, params := sm.SceneName(sm.Current()); name != "menu" || params["id"] != 1 {
+		t.Errorf("Expect menu with id 1, got %q %v", name, params)
+	}
+}
+
+func TestSceneTransitionPolicy(t *testing.T) {
+	a := &testScene{name: "a"}
+	b := &testScene{name: "b"}
+	c := &testScene{name: "c"}
+
+	sm := NewSceneManager(10, 10, 2)
+	sm.SetTransitionPolicy(TransitionQueue)
+	var completed []string
+	sm.SetOnTransitionComplete(func(from, to Scene) {
+		completed = append(completed, fmt.Sprintf("%v>%v", from, to))
+	})
+	sm.GoTo(a)
+	sm.GoTo(b)
+	sm.GoTo(c)
+	for i := 0; i < 4; i++ {
+		sm.Update()
+	}
+	if sm.Current() != c {
+		t.Errorf("Expect c after queued transitions, got %v", sm.Current())
+	}
+	if !equalEvents(completed, []string{"a>b", "b>c"}) {
+		t.Errorf("Expect [a>b b>c], got %v", completed)
+	}
+
+	sm = NewSceneManager(10, 10, 2)
+	sm.SetTransitionPolicy(TransitionIgnore)
+	a, b, c = &testScene{name: "a"}, &testScene{name: "b"}, &testScene{name: "c"}
+	sm.GoTo(a)
+	sm.GoTo(b)
+	sm.GoTo(c)
+	sm.Update()
+	sm.Update()
+	if sm.Current() != b {
+		t.Errorf("Expect b, got %v", sm.Current())
+	}
+	if len(c.events) != 0 {
+		t.Errorf("Expect ignored scene untouched, got %v", c.events)
+	}
+}
+
+func TestScenePushDuringTransition(t *testing.T) {
+	a := &testScene{name: "a"}
+	b := &testScene{name: "b"}
+	menu := &testScene{name: "menu"}
+	sm := NewSceneManager(10, 10, 10)
+	sm.GoTo(a)
+	sm.GoTo(b)
+	for i := 0; i < 8; i++ {
+		sm.Update()
+	}
+	sm.Push(menu)
+	sm.Update()
+	if !sm.IsTransitioning() {
+		t.Fatal("Expect transition running")
+	}
+	sm.Update()
+	if sm.IsTransitioning() || sm.Current() != menu {
+		t.Errorf("Expect menu after the remaining 2 frames, got %v", sm.Current())
+	}
+}
+
+func TestSceneTransitionUpdates(t *testing.T) {
+	a := &testScene{name: "a"}
+	b := &testScene{name: "b"}
+	sm := NewSceneManager(10, 10, 3)
+	sm.SetTransitionUpdates(true, true)
+	sm.GoTo(a)
+	sm.GoTo(b)
+	for i := 0; i < 3; i++ {
+		sm.Update()
+	}
+	if a.updates != 3 || b.updates != 3 {
+		t.Errorf("Expect 3 updates each, got %d and %d", a.updates, b.updates)
+	}
+	if !equalEvents(a.events, []string{"enter", "pause", "exit"}) {
+		t.Errorf("Expect [enter pause exit], got %v", a.events)
+	}
+}
+
+// testLayoutScene has a fixed logical screen size
+type testLayoutScene struct {
+	testScene
+}
+
+func (s *testLayoutScene) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return outsideWidth / 4, outsideHeight / 4
+}
+
+func TestSceneLayout(t *testing.T) {
+	sm := NewSceneManager(320, 240, 2)
+	sm.GoTo(&testScene{name: "a"})
+	if w, h := sm.Layout(640, 480); w != 320 || h != 240 {
+		t.Errorf("Expect (320,240), got (%d,%d)", w, h)
+	}
+	sm.GoTo(&testLayoutScene{testScene{name: "b"}}, WithDuration(0))
+	if w, h := sm.Layout(640, 480); w != 160 || h != 120 {
+		t.Errorf("Expect (160,120), got (%d,%d)", w, h)
+	}
+
+	sm = NewSceneManager(0, 0, 2)
+	sm.GoTo(&testScene{name: "a"})
+	if w, h := sm.Layout(640, 480); w != 640 || h != 480 {
+		t.Errorf("Expect (640,480), got (%d,%d)", w, h)
+	}
+	sm.GoTo(&testLayoutScene{testScene{name: "b"}}, WithDuration(0))
+	if w, h := sm.Layout(640, 480); w != 160 || h != 120 {
+		t.Errorf("Expect (160,120), got (%d,%d)", w, h)
+	}
+
+	// transition buffers follow the screen size
+	sm.GoTo(&testScene{name: "c"})
+	if !sm.IsTransitioning() {
+		t.Fatal("Expect transition running")
+	}
+	for _, size := range []image.Point{{32, 24}, {40, 30}} {
+		sm.Draw(ebiten.NewImage(size.X, size.Y))
+		for _, b := range []*ebiten.Image{sm.transitionFrom, sm.transitionTo} {
+			if b == nil || b.Bounds().Size() != size {
+				t.Fatalf("Expect transition buffers of size %v", size)
+			}
+		}
+	}
+}
+
+func TestLoadingScene(t *testing.T) {
+	sm := NewSceneManager(10, 10, 0)
+	target := &testScene{name: "level"}
+	loaded := make(chan struct{})
+	l := NewLoadingScene(sm, func() (Scene, error) { return target, nil },
+		LoadTask{Name: "a", Load: func() error { return nil }},
+		LoadTask{Name: "b", Load: func() error { <-loaded; return nil }},
+	)
+	sm.GoTo(l)
+	sm.Update()
+	if sm.Current() != l {
+		t.Fatalf("Expect loading scene, got %v", sm.Current())
+	}
+	close(loaded)
+	for i := 0; i < 1000 && sm.Current() == l; i++ {
+		time.Sleep(time.Millisecond)
+		sm.Update()
+	}
+	if sm.Current() != target {
+		t.Errorf("Expect level after loading, got %v", sm.Current())
+	}
+	if p := l.Progress(); p.Done != 2 || p.Total != 2 || p.Fraction() != 1 {
+		t.Errorf("Expect 2 of 2 done, got %+v", p)
+	}
+}
+
+func TestLoadingSceneIgnorePolicy(t *testing.T) {
+	sm := NewSceneManager(10, 10, 5)
+	sm.SetTransitionPolicy(TransitionIgnore)
+	sm.SetTransitionUpdates(false, true)
+	target := &testScene{name: "level"}
+	l := NewLoadingScene(sm, func() (Scene, error) { return target, nil })
+	sm.GoTo(&testScene{name: "title"})
+	sm.GoTo(l)
+	for i := 0; i < 20 && sm.Current() != target; i++ {
+		sm.Update()
+	}
+	if sm.Current() != target {
+		t.Errorf("Expect level after loading during transition, got %v", sm.Current())
+	}
+}
+
+func TestLoadingSceneError(t *testing.T) {
+	sm := NewSceneManager(10, 10, 0)
+	crash := &testScene{name: "crash"}
+	l := NewLoadingScene(sm, func() (Scene, error) { return &testScene{}, nil },
+		LoadTask{Name: "a", Load: func() error { return errors.New("missing file") }},
+	)
+	var got []error
+	l.SetErrorScene(func(errs []error) Scene {
+		got = errs
+		return crash
+	})
+	sm.GoTo(l)
+	for i := 0; i < 1000 && sm.Current() == l; i++ {
+		time.Sleep(time.Millisecond)
+		sm.Update()
+	}
+	if sm.Current() != crash {
+		t.Errorf("Expect crash scene, got %v", sm.Current())
+	}
+	if len(got) != 1 {
+		t.Errorf("Expect 1 error, got %v", got)
+	}
+
+	l = NewLoadingScene(sm, func() (Scene, error) { return &testScene{}, nil },
+		LoadTask{Name: "a", Load: func() error { return errors.New("missing file") }},
+	)
+	l.SetErrorScene(func(errs []error) Scene { return nil })
+	sm.GoTo(l)
+	var err error
+	for i := 0; i < 1000 && err == nil; i++ {
+		time.Sleep(time.Millisecond)
+		err = sm.Update()
+	}
+	if err == nil || sm.Current() != l {
+		t.Errorf("Expect errors returned without error scene, got %v, %v", err, sm.Current())
+	}
+}
+
+// testRequestScene returns err from Update once
+type testRequestScene struct {
+	testScene
+	err error
+}
+
+func (s *testRequestScene) Update() error {
+	s.updates++
+	err := s.err
+	s.err = nil
+	return err
+}
+
+func TestSceneRequest(t *testing.T) {
+	sm := NewSceneManager(10, 10, 0)
+	b := &testScene{name: "b"}
+	a := &testRequestScene{testScene: testScene{name: "a"}, err: GoToScene(b)}
+	sm.GoTo(a)
+	if err := sm.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if sm.Current() != b {
+		t.Errorf("Expect b, got %v", sm.Current())
+	}
+
+	menu := &testRequestScene{testScene: testScene{name: "menu"}, err: ErrPopScene}
+	sm.Push(menu)
+	sm.Update()
+	if sm.Current() != b {
+		t.Errorf("Expect menu popped, got %v", sm.Current())
+	}
+
+	created := 0
+	sm.Register("level", func(params SceneParams) (Scene, error) {
+		created++
+		return &testRequestScene{testScene: testScene{name: "level"}, err: ErrRestartScene}, nil
+	})
+	sm.GoToNamed("level", nil)
+	level := sm.Current()
+	sm.Update()
+	if created != 2 || sm.Current() == level {
+		t.Errorf("Expect level created again, got %d created", created)
+	}
+
+	sm.Push(&testRequestScene{err: ErrQuit})
+	if err := sm.Update(); err != ebiten.Termination {
+		t.Errorf("Expect ebiten.Termination, got %v", err)
+	}
+	if sm.Current() != nil {
+		t.Errorf("Expect no scene after quit, got %v", sm.Current())
+	}
+}
+
+// testFuncScene calls update from Update
+type testFuncScene struct {
+	testScene
+	update func()
+}
+
+func (s *testFuncScene) Update() error {
+	s.update()
+	return nil
+}
+
+func TestSceneUpdateAfterChange(t *testing.T) {
+	sm := NewSceneManager(10, 10, 0)
+	b := &testScene{name: "b"}
+	game := &testFuncScene{testScene: testScene{name: "game"}, update: func() { sm.GoTo(b) }}
+	hud := &testOverlay{testScene: testScene{name: "hud"}, updateBelow: true}
+	sm.GoTo(game)
+	sm.Push(hud)
+	if err := sm.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if sm.Current() != b {
+		t.Errorf("Expect b, got %v", sm.Current())
+	}
+	if !equalEvents(hud.events, []string{"enter", "pause", "exit"}) {
+		t.Errorf("Expect [enter pause exit], got %v", hud.events)
+	}
+	if hud.updates != 0 {
+		t.Errorf("Expect exited hud not updated, got %d updates", hud.updates)
+	}
+}
+
+func TestSceneErrorHandler(t *testing.T) {
+	sm := NewSceneManager(10, 10, 0)
+	crash := &testScene{name: "crash"}
+	boom := errors.New("boom")
+	sm.GoTo(&testRequestScene{testScene: testScene{name: "a"}, err: boom})
+	var got error
+	sm.SetErrorHandler(func(scene Scene, err error) Scene {
+		got = err
+		return crash
+	})
+	if err := sm.Update(); err != nil {
+		t.Fatalf("Expect error handled, got %v", err)
+	}
+	if got != boom || sm.Current() != crash {
+		t.Errorf("Expect crash scene for boom, got %v and %v", sm.Current(), got)
+	}
+
+	sm.SetErrorHandler(func(scene Scene, err error) Scene { return nil })
+	sm.GoTo(&testRequestScene{err: boom})
+	if err := sm.Update(); err != boom {
+		t.Errorf("Expect boom, got %v", err)
+	}
+}
+
+// testStatefulScene saves its number of updates
+type testStatefulScene struct {
+	testScene
+}
+
+func (s *testStatefulScene) SaveState() ([]byte, error) {
+	return []byte(fmt.Sprint(s.updates)), nil
+}
+
+func (s *testStatefulScene) RestoreState(data []byte) error {
+	_, err := fmt.Sscan(string(data), &s.updates)
+	return err
+}
+
+func TestSceneSnapshot(t *testing.T) {
+	newManager := func() *SceneManager {
+		sm := NewSceneManager(10, 10, 0)
+		sm.Register("level", func(params SceneParams) (Scene, error) {
+			return &testStatefulScene{testScene{name: fmt.Sprint("level", params.Int("id"))}}, nil
+		})
+		sm.Register("menu", func(params SceneParams) (Scene, error) {
+			return &testScene{name: "menu"}, nil
+		})
+		return sm
+	}
+
+	sm := newManager()
+	sm.GoToNamed("level", SceneParams{"id": 2})
+	for i := 0; i < 5; i++ {
+		sm.Update()
+	}
+	sm.PushNamed("menu", nil)
+	data, err := sm.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newManager()
+	if err := restored.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Current().(*testScene).name != "menu" {
+		t.Errorf("Expect menu on top, got %v", restored.Current())
+	}
+	restored.Pop()
+	level := restored.Current().(*testStatefulScene)
+	if level.name != "level2" || level.updates != 5 {
+		t.Errorf("Expect level2 with 5 updates, got %s with %d", level.name, level.updates)
+	}
+
+	params := SceneParams{"int": 2, "float": 1.5, "json": 2.0, "number": json.Number("3")}
+	if params.Int("int") != 2 || params.Int("json") != 2 || params.Int("number") != 3 ||
+		params.Float("float") != 1.5 || params.Float("int") != 2 || params.Int("missing") != 0 {
+		t.Errorf("Expect numbers of any type, got %v", params)
+	}
+
+	sm.Push(&testScene{})
+	if _, err := sm.Snapshot(); !errors.Is(err, ErrSceneNotNamed) {
+		t.Errorf("Expect ErrSceneNotNamed, got %v", err)
+	}
+}
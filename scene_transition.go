@@ -0,0 +1,260 @@
+package dango
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Transition draws the switch between two scenes, `from` and `to` are the
+// rendered scenes with the same size as `screen`, progress goes from 0 to 1
+type Transition interface {
+	Draw(screen, from, to *ebiten.Image, progress float64)
+}
+
+// Direction the incoming scene moves to
+type Direction int
+
+const (
+	DirectionLeft Direction = iota
+	DirectionRight
+	DirectionUp
+	DirectionDown
+)
+
+// TransitionOption changes the transition of a single GoTo or Replace call
+type TransitionOption func(*transitionConfig)
+
+type transitionConfig struct {
+	transition Transition
+	frames     int
+}
+
+// WithTransition use `t` instead of the SceneManager default transition
+func WithTransition(t Transition) TransitionOption {
+	return func(c *transitionConfig) {
+		c.transition = t
+	}
+}
+
+// WithDuration set number of frames of the transition
+func WithDuration(frames int) TransitionOption {
+	return func(c *transitionConfig) {
+		c.frames = frames
+	}
+}
+
+// Crossfade blends the incoming scene over the outgoing scene
+type Crossfade struct {
+	Ease Easing
+}
+
+func (t Crossfade) Draw(screen, from, to *ebiten.Image, progress float64) {
+	p := ease(t.Ease, progress)
+	screen.DrawImage(from, nil)
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(float32(p))
+	screen.DrawImage(to, op)
+}
+
+// FadeThrough fades the outgoing scene to `Color`, then fades in the incoming
+// scene, black if Color is nil
+type FadeThrough struct {
+	Color color.Color
+	Ease  Easing
+}
+
+func (t FadeThrough) Draw(screen, from, to *ebiten.Image, progress float64) {
+	p := ease(t.Ease, progress)
+	c := t.Color
+	if c == nil {
+		c = color.Black
+	}
+	alpha := p * 2
+	if p < 0.5 {
+		screen.DrawImage(from, nil)
+	} else {
+		screen.DrawImage(to, nil)
+		alpha = (1 - p) * 2
+	}
+	w, h := screenSize(screen)
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), scaleColor(c, alpha), false)
+}
+
+// Slide moves the incoming scene in from the edge, in `Direction`, if `Push`,
+// the outgoing scene is pushed out the opposite edge
+type Slide struct {
+	Direction Direction
+	Push      bool
+	Ease      Easing
+}
+
+func (t Slide) Draw(screen, from, to *ebiten.Image, progress float64) {
+	p := ease(t.Ease, progress)
+	w, h := screenSize(screen)
+	dx, dy := directionVector(t.Direction)
+
+	op := &ebiten.DrawImageOptions{}
+	if t.Push {
+		op.GeoM.Translate(dx*w*p, dy*h*p)
+	}
+	screen.DrawImage(from, op)
+
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-dx*w*(1-p), -dy*h*(1-p))
+	screen.DrawImage(to, op)
+}
+
+// Wipe reveals the incoming scene with an edge moving in `Direction`
+type Wipe struct {
+	Direction Direction
+	Ease      Easing
+}
+
+func (t Wipe) Draw(screen, from, to *ebiten.Image, progress float64) {
+	p := ease(t.Ease, progress)
+	screen.DrawImage(from, nil)
+
+	b := to.Bounds()
+	w, h := b.Dx(), b.Dy()
+	r := b
+	switch t.Direction {
+	case DirectionLeft:
+		r.Min.X = b.Max.X - int(math.Round(float64(w)*p))
+	case DirectionRight:
+		r.Max.X = b.Min.X + int(math.Round(float64(w)*p))
+	case DirectionUp:
+		r.Min.Y = b.Max.Y - int(math.Round(float64(h)*p))
+	case DirectionDown:
+		r.Max.Y = b.Min.Y + int(math.Round(float64(h)*p))
+	}
+	if r.Empty() {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(r.Min.X-b.Min.X), float64(r.Min.Y-b.Min.Y))
+	screen.DrawImage(to.SubImage(r).(*ebiten.Image), op)
+}
+
+// Iris reveals the incoming scene through a growing circle at the centre
+// of the screen, if `Close`, the outgoing scene shrinks into a circle instead
+type Iris struct {
+	Close bool
+	Ease  Easing
+
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+func (t *Iris) Draw(screen, from, to *ebiten.Image, progress float64) {
+	p := ease(t.Ease, progress)
+	w, h := screenSize(screen)
+	radius := math.Hypot(w, h) / 2
+
+	bottom, top := from, to
+	if t.Close {
+		bottom, top = to, from
+		p = 1 - p
+	}
+	screen.DrawImage(bottom, nil)
+	if p <= 0 {
+		return
+	}
+
+	var path vector.Path
+	path.Arc(float32(w/2), float32(h/2), float32(radius*p), 0, 2*math.Pi, vector.Clockwise)
+	path.Close()
+	t.vertices, t.indices = path.AppendVerticesAndIndicesForFilling(t.vertices[:0], t.indices[:0])
+	ox, oy := float32(top.Bounds().Min.X), float32(top.Bounds().Min.Y)
+	for i := range t.vertices {
+		t.vertices[i].SrcX = t.vertices[i].DstX + ox
+		t.vertices[i].SrcY = t.vertices[i].DstY + oy
+		t.vertices[i].ColorR = 1
+		t.vertices[i].ColorG = 1
+		t.vertices[i].ColorB = 1
+		t.vertices[i].ColorA = 1
+	}
+	screen.DrawTriangles(t.vertices, t.indices, top, &ebiten.DrawTrianglesOptions{
+		FillRule:  ebiten.NonZero,
+		AntiAlias: true,
+	})
+}
+
+// Pixelate breaks the outgoing scene into blocks up to `MaxBlock` pixels,
+// then resolves the incoming scene from the blocks, default 32 pixels
+type Pixelate struct {
+	MaxBlock int
+	Ease     Easing
+
+	buffer *ebiten.Image
+}
+
+func (t *Pixelate) Draw(screen, from, to *ebiten.Image, progress float64) {
+	p := ease(t.Ease, progress)
+	maxBlock := t.MaxBlock
+	if maxBlock <= 0 {
+		maxBlock = 32
+	}
+	src := from
+	if p >= 0.5 {
+		src = to
+	}
+	block := 1 + float64(maxBlock-1)*(1-math.Abs(2*p-1))
+	block = math.Round(block)
+	if block <= 1 {
+		screen.DrawImage(src, nil)
+		return
+	}
+
+	b := src.Bounds()
+	if t.buffer == nil || t.buffer.Bounds().Size() != b.Size() {
+		if t.buffer != nil {
+			t.buffer.Deallocate()
+		}
+		t.buffer = ebiten.NewImage(b.Dx(), b.Dy())
+	}
+	t.buffer.Clear()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(1/block, 1/block)
+	t.buffer.DrawImage(src, op)
+
+	small := image.Rect(0, 0,
+		int(math.Ceil(float64(b.Dx())/block)), int(math.Ceil(float64(b.Dy())/block)))
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(block, block)
+	screen.DrawImage(t.buffer.SubImage(small).(*ebiten.Image), op)
+}
+
+// directionVector return unit vector of `d` on screen
+func directionVector(d Direction) (float64, float64) {
+	switch d {
+	case DirectionLeft:
+		return -1, 0
+	case DirectionRight:
+		return 1, 0
+	case DirectionUp:
+		return 0, -1
+	default:
+		return 0, 1
+	}
+}
+
+func screenSize(screen *ebiten.Image) (float64, float64) {
+	s := screen.Bounds().Size()
+	return float64(s.X), float64(s.Y)
+}
+
+// scaleColor return premultiplied color `c` with alpha scaled by `a`
+func scaleColor(c color.Color, a float64) color.Color {
+	a = Clamp01(a)
+	r, g, b, al := c.RGBA()
+	return color.RGBA64{
+		R: uint16(float64(r) * a),
+		G: uint16(float64(g) * a),
+		B: uint16(float64(b) * a),
+		A: uint16(float64(al) * a),
+	}
+}
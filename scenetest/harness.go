@@ -0,0 +1,184 @@
+// Package scenetest steps scenes and SceneManager without a window, with
+// scripted input, so gameplay flows can be tested with go test.
+//
+//	h := scenetest.New(t, NewTitleScene())
+//	h.Click(100, 50)
+//	h.Step(60)
+//	if _, ok := h.Current().(*LevelScene); !ok { t.Error("expect level") }
+package scenetest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/rtpchan/dango"
+	"github.com/rtpchan/dango/input"
+)
+
+// Transition records a change of the current scene, Start and End are the
+// ticks the transition started and completed, equal for immediate changes
+type Transition struct {
+	From  dango.Scene
+	To    dango.Scene
+	Start int
+	End   int
+}
+
+func (t Transition) String() string {
+	return fmt.Sprintf("%v -> %v [%d-%d]", t.From, t.To, t.Start, t.End)
+}
+
+// Harness steps a SceneManager tick by tick
+type Harness struct {
+	manager *dango.SceneManager
+	input   *Input
+	tick    int
+	script  map[int][]func(in *Input)
+
+	history     []dango.Scene // current scene after each tick
+	transitions []Transition
+	open        *Transition // transition in progress
+	current     dango.Scene
+}
+
+// New create a harness stepping `scene` in its own SceneManager without transition
+func New(t testing.TB, scene dango.Scene) *Harness {
+	sm := dango.NewSceneManager(0, 0, 0)
+	sm.GoTo(scene)
+	return NewManager(t, sm)
+}
+
+// NewManager create a harness stepping `sm`, scripted input replaces
+// ebiten input until the test finishes
+func NewManager(t testing.TB, sm *dango.SceneManager) *Harness {
+	h := &Harness{
+		manager: sm,
+		input:   NewInput(),
+		script:  map[int][]func(in *Input){},
+		current: sm.Current(),
+	}
+	previous := input.CurrentSource()
+	input.SetSource(h.input)
+	t.Cleanup(func() { input.SetSource(previous) })
+	return h
+}
+
+// Manager return the stepped SceneManager
+func (h *Harness) Manager() *dango.SceneManager {
+	return h.manager
+}
+
+// Input return the scripted input, changes apply from the next tick
+func (h *Harness) Input() *Input {
+	return h.input
+}
+
+// Tick return number of ticks stepped
+func (h *Harness) Tick() int {
+	return h.tick
+}
+
+// Current return the current scene of the SceneManager
+func (h *Harness) Current() dango.Scene {
+	return h.manager.Current()
+}
+
+// History return the current scene after each tick
+func (h *Harness) History() []dango.Scene {
+	return h.history
+}
+
+// Transitions return completed changes of the current scene
+func (h *Harness) Transitions() []Transition {
+	return h.transitions
+}
+
+// At schedules `f` to change input before Update of `tick`
+func (h *Harness) At(tick int, f func(in *Input)) {
+	h.script[tick] = append(h.script[tick], f)
+}
+
+// Click presses left mouse button at x, y on the next tick, and releases
+// it on the tick after
+func (h *Harness) Click(x, y int) {
+	h.At(h.tick, func(in *Input) {
+		in.MoveCursor(x, y)
+		in.PressMouse(ebiten.MouseButtonLeft)
+	})
+	h.At(h.tick+1, func(in *Input) { in.ReleaseMouse(ebiten.MouseButtonLeft) })
+}
+
+// TapKey presses `k` on the next tick, and releases it on the tick after
+func (h *Harness) TapKey(k ebiten.Key) {
+	h.At(h.tick, func(in *Input) { in.PressKey(k) })
+	h.At(h.tick+1, func(in *Input) { in.ReleaseKey(k) })
+}
+
+// Step runs Update `n` times, stops at the first error
+func (h *Harness) Step(n int) error {
+	for i := 0; i < n; i++ {
+		for _, f := range h.script[h.tick] {
+			f(h.input)
+		}
+		delete(h.script, h.tick)
+
+		err := h.manager.Update()
+		h.record()
+		h.input.endTick()
+		h.tick++
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StepUntil runs Update until `cond` is true, at most `max` times,
+// return an error if `cond` is not met
+func (h *Harness) StepUntil(max int, cond func(h *Harness) bool) error {
+	for i := 0; i < max; i++ {
+		if cond(h) {
+			return nil
+		}
+		if err := h.Step(1); err != nil {
+			return err
+		}
+	}
+	if cond(h) {
+		return nil
+	}
+	return fmt.Errorf("condition not met after %d ticks", max)
+}
+
+// record the current scene and transitions after a tick
+func (h *Harness) record() {
+	current := h.manager.Current()
+	h.history = append(h.history, current)
+
+	changed := !sameScene(current, h.current)
+	if h.open != nil && (changed || !h.manager.IsTransitioning()) {
+		h.open.To = current
+		h.open.End = h.tick
+		h.transitions = append(h.transitions, *h.open)
+		h.open = nil
+	} else if changed {
+		h.transitions = append(h.transitions,
+			Transition{From: h.current, To: current, Start: h.tick, End: h.tick})
+	}
+	if h.open == nil && h.manager.IsTransitioning() {
+		h.open = &Transition{From: current, Start: h.tick}
+	}
+	h.current = current
+}
+
+func sameScene(a, b dango.Scene) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,73 @@
+package scenetest
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/rtpchan/dango"
+	"github.com/rtpchan/dango/input"
+)
+
+type title struct {
+	sm    *dango.SceneManager
+	level dango.Scene
+}
+
+func (s *title) Update() error {
+	if input.IsKeyJustPressed(ebiten.KeyEnter) {
+		s.sm.GoTo(s.level)
+	}
+	return nil
+}
+
+func (s *title) Draw(screen *ebiten.Image) {}
+
+type level struct {
+	x, y   int
+	scroll float64
+	clicks int
+}
+
+func (s *level) Update() error {
+	s.x, s.y = input.CursorPosition()
+	_, dy := input.Wheel()
+	s.scroll += dy
+	if input.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		s.clicks++
+	}
+	return nil
+}
+
+func (s *level) Draw(screen *ebiten.Image) {}
+
+func TestHarness(t *testing.T) {
+	sm := dango.NewSceneManager(0, 0, 10)
+	lv := &level{}
+	ti := &title{sm: sm, level: lv}
+	sm.GoTo(ti)
+	h := NewManager(t, sm)
+
+	h.Step(5)
+	if h.Current() != ti {
+		t.Fatalf("Expect title, got %v", h.Current())
+	}
+	h.TapKey(ebiten.KeyEnter)
+	if err := h.StepUntil(100, func(h *Harness) bool { return h.Current() == lv }); err != nil {
+		t.Fatal(err)
+	}
+	tr := h.Transitions()
+	if len(tr) != 1 || tr[0].From != ti || tr[0].To != lv || tr[0].Start != 5 || tr[0].End != 15 {
+		t.Errorf("Expect title to level from tick 5 to 15, got %v", tr)
+	}
+
+	h.Click(30, 40)
+	h.At(h.Tick()+1, func(in *Input) { in.Scroll(0, 2) })
+	h.Step(3)
+	if lv.clicks != 1 || lv.x != 30 || lv.y != 40 || lv.scroll != 2 {
+		t.Errorf("Expect 1 click at (30,40) and scroll 2, got %d at (%d,%d) and %f",
+			lv.clicks, lv.x, lv.y, lv.scroll)
+	}
+	if len(h.History()) != h.Tick() {
+		t.Errorf("Expect history of %d ticks, got %d", h.Tick(), len(h.History()))
+	}
+}
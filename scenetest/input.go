@@ -0,0 +1,125 @@
+package scenetest
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Input is a scripted input.Source, state changes take effect on the next
+// Update, just pressed/released compare with the previous tick
+type Input struct {
+	cursorX, cursorY int
+	wheelX, wheelY   float64
+	mouse, prevMouse map[ebiten.MouseButton]bool
+	keys, prevKeys   map[ebiten.Key]bool
+	touches          map[ebiten.TouchID][2]int
+}
+
+func NewInput() *Input {
+	return &Input{
+		mouse:     map[ebiten.MouseButton]bool{},
+		prevMouse: map[ebiten.MouseButton]bool{},
+		keys:      map[ebiten.Key]bool{},
+		prevKeys:  map[ebiten.Key]bool{},
+		touches:   map[ebiten.TouchID][2]int{},
+	}
+}
+
+// MoveCursor to screen position x, y
+func (in *Input) MoveCursor(x, y int) {
+	in.cursorX, in.cursorY = x, y
+}
+
+// Scroll the wheel by x, y for one tick
+func (in *Input) Scroll(x, y float64) {
+	in.wheelX += x
+	in.wheelY += y
+}
+
+func (in *Input) PressMouse(b ebiten.MouseButton) {
+	in.mouse[b] = true
+}
+
+func (in *Input) ReleaseMouse(b ebiten.MouseButton) {
+	delete(in.mouse, b)
+}
+
+func (in *Input) PressKey(k ebiten.Key) {
+	in.keys[k] = true
+}
+
+func (in *Input) ReleaseKey(k ebiten.Key) {
+	delete(in.keys, k)
+}
+
+// Touch puts or moves touch `id` at screen position x, y
+func (in *Input) Touch(id ebiten.TouchID, x, y int) {
+	in.touches[id] = [2]int{x, y}
+}
+
+func (in *Input) ReleaseTouch(id ebiten.TouchID) {
+	delete(in.touches, id)
+}
+
+// endTick remembers the state for just pressed/released, and clears the wheel
+func (in *Input) endTick() {
+	in.prevMouse = copyMap(in.mouse)
+	in.prevKeys = copyMap(in.keys)
+	in.wheelX, in.wheelY = 0, 0
+}
+
+func copyMap[K comparable](m map[K]bool) map[K]bool {
+	c := make(map[K]bool, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func (in *Input) CursorPosition() (int, int) {
+	return in.cursorX, in.cursorY
+}
+
+func (in *Input) Wheel() (float64, float64) {
+	return in.wheelX, in.wheelY
+}
+
+func (in *Input) IsMouseButtonPressed(b ebiten.MouseButton) bool {
+	return in.mouse[b]
+}
+
+func (in *Input) IsMouseButtonJustPressed(b ebiten.MouseButton) bool {
+	return in.mouse[b] && !in.prevMouse[b]
+}
+
+func (in *Input) IsMouseButtonJustReleased(b ebiten.MouseButton) bool {
+	return !in.mouse[b] && in.prevMouse[b]
+}
+
+func (in *Input) IsKeyPressed(k ebiten.Key) bool {
+	return in.keys[k]
+}
+
+func (in *Input) IsKeyJustPressed(k ebiten.Key) bool {
+	return in.keys[k] && !in.prevKeys[k]
+}
+
+func (in *Input) IsKeyJustReleased(k ebiten.Key) bool {
+	return !in.keys[k] && in.prevKeys[k]
+}
+
+// AppendTouchIDs appends touches in id order
+func (in *Input) AppendTouchIDs(touches []ebiten.TouchID) []ebiten.TouchID {
+	start := len(touches)
+	for id := range in.touches {
+		touches = append(touches, id)
+	}
+	slices.Sort(touches[start:])
+	return touches
+}
+
+func (in *Input) TouchPosition(id ebiten.TouchID) (int, int) {
+	p := in.touches[id]
+	return p[0], p[1]
+}
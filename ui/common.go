@@ -2,7 +2,7 @@ package ui
 
 import (
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/rtpchan/dango/input"
 )
 
 type Drawable interface {
@@ -46,7 +46,7 @@ func (b *UI) SetPos(x, y int) {
 }
 
 func (b *UI) IsMouseOnButton() bool {
-	mx, my := ebiten.CursorPosition()
+	mx, my := input.CursorPosition()
 	if mx >= b.PosX && mx <= (b.PosX+b.ImgW) &&
 		my >= b.PosY && my <= (b.PosY+b.ImgH) {
 		return true
@@ -60,7 +60,7 @@ func (b *UI) IsHover() bool {
 
 func (b *UI) IsJustReleased() bool {
 	if b.Active &&
-		inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) &&
+		input.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) &&
 		b.IsMouseOnButton() {
 		return true
 	}
@@ -69,7 +69,7 @@ func (b *UI) IsJustReleased() bool {
 
 func (b *UI) IsJustPressed() bool {
 	if b.Active &&
-		inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) &&
+		input.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) &&
 		b.IsMouseOnButton() {
 		return true
 	}
@@ -78,7 +78,7 @@ func (b *UI) IsJustPressed() bool {
 
 func (b *UI) IsDown() bool {
 	if b.Active &&
-		ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) &&
+		input.IsMouseButtonPressed(ebiten.MouseButtonLeft) &&
 		b.IsMouseOnButton() {
 		return true
 	}
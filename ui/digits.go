@@ -7,6 +7,7 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/rtpchan/dango/input"
 	"golang.org/x/image/font"
 )
 
@@ -90,7 +91,7 @@ func (d *Digit) Draw(screen *ebiten.Image) {
 
 func (d *Digit) Update() {
 	if d.IsMouseOnButton() {
-		_, y := ebiten.Wheel()
+		_, y := input.Wheel()
 		if y >= 1 {
 			d.number += 1
 			if d.number == 10 {